@@ -23,20 +23,26 @@ func (a *API) addWSChannel(
 	info *RouteInfo,
 	pathFields map[string]reflect.StructField,
 	errors []errorEntry,
+	security *securityConfig,
 ) error {
 	channelItem := spec.ChannelItem{}
+	subID, pubID := operationID("subscribe", path), operationID("publish", path)
+	if info != nil && info.OperationID != "" {
+		subID, pubID = "subscribe"+capitalize(info.OperationID), "publish"+capitalize(info.OperationID)
+	}
 
 	// Path parameters.
 	for _, match := range pathParamRe.FindAllStringSubmatch(path, -1) {
 		name := match[1]
-		paramSchema := map[string]interface{}{"type": "string"}
+		param := spec.Parameter{Schema: map[string]interface{}{"type": "string"}}
 		if field, ok := pathFields[name]; ok {
-			paramSchema = goTypeToJSONSchema(field.Type)
+			param.Schema = goTypeToJSONSchema(field.Type)
+			param.Description = fieldDescription(field.Tag)
 		}
 		if channelItem.Parameters == nil {
 			channelItem.Parameters = make(map[string]spec.Parameter)
 		}
-		channelItem.Parameters[name] = spec.Parameter{Schema: paramSchema}
+		channelItem.Parameters[name] = param
 	}
 
 	// Subscribe = what clients receive = our Send type (server -> client).
@@ -45,8 +51,9 @@ func (a *API) addWSChannel(
 		if err != nil {
 			return fmt.Errorf("send message: %w", err)
 		}
+		a.claimOperationID(subID, "subscribe "+path)
 		channelItem.Subscribe = &spec.Operation{
-			ID:      operationID("subscribe", path),
+			ID:      subID,
 			Message: subMsg,
 		}
 	}
@@ -57,14 +64,25 @@ func (a *API) addWSChannel(
 		if err != nil {
 			return fmt.Errorf("recv message: %w", err)
 		}
+		a.claimOperationID(pubID, "publish "+path)
 		channelItem.Publish = &spec.Operation{
-			ID:      operationID("publish", path),
+			ID:      pubID,
 			Message: pubMsg,
 		}
 	}
 
+	// Security requirements, checked on the handshake before the upgrade.
+	if reqs := a.addAsyncSecurityRequirements(security); reqs != nil {
+		for _, op := range []*spec.Operation{channelItem.Subscribe, channelItem.Publish} {
+			if op != nil {
+				op.Security = reqs
+			}
+		}
+	}
+
 	if info != nil {
 		channelItem.Description = info.Description
+		channelItem.Deprecated = info.Deprecation != nil
 		for _, op := range []*spec.Operation{channelItem.Subscribe, channelItem.Publish} {
 			if op == nil {
 				continue
@@ -0,0 +1,149 @@
+package shiftapi_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type NoteInput struct {
+	Text string `json:"text"`
+}
+
+type NoteResult struct {
+	Length int `json:"length"`
+}
+
+func createNote(r *http.Request, in NoteInput) (*NoteResult, error) {
+	return &NoteResult{Length: len(in.Text)}, nil
+}
+
+func jsonBody(n int) string {
+	return `{"text":"` + strings.Repeat("a", n) + `"}`
+}
+
+func TestMaxBodySize_JSON(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithMaxBodySize(64))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequest(t, api, http.MethodPost, "/notes", jsonBody(10))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doRequest(t, api, http.MethodPost, "/notes", jsonBody(100))
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	body := decodeJSON[map[string]string](t, resp)
+	if body["message"] != "request entity too large" {
+		t.Errorf("unexpected message %q", body["message"])
+	}
+}
+
+func TestMaxBodySize_routeOverridesGroup(t *testing.T) {
+	api := newTestAPI(t)
+	g := api.Group("/v1", shiftapi.WithMaxBodySize(16))
+	shiftapi.Handle(g, "POST /small", createNote)
+	shiftapi.Handle(g, "POST /large", createNote, shiftapi.WithMaxBodySize(1024))
+
+	if resp := doRequest(t, api, http.MethodPost, "/v1/small", jsonBody(100)); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 from group limit, got %d", resp.StatusCode)
+	}
+	if resp := doRequest(t, api, http.MethodPost, "/v1/large", jsonBody(100)); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 with raised route limit, got %d", resp.StatusCode)
+	}
+}
+
+func TestMaxBodySize_form(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithMaxBodySize(256))
+	shiftapi.Handle(api, "POST /upload", func(r *http.Request, in UploadInput) (*UploadResult, error) {
+		return &UploadResult{Filename: in.File.Filename}, nil
+	})
+
+	resp := doMultipartRequest(t, api, http.MethodPost, "/upload",
+		map[string]string{"title": "big"},
+		map[string][]byte{"file": []byte(strings.Repeat("x", 1024))},
+	)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestMaxBodySize_rawBody(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithMaxBodySize(8))
+	shiftapi.Handle(api, "POST /hooks/{source}", func(r *http.Request, in WebhookInput) (*RawBodyResult, error) {
+		return &RawBodyResult{Size: len(in.Payload)}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/hooks/x", "0123456789", map[string]string{"Content-Type": "application/octet-stream", "X-Signature": "s"})
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestMaxBodySize_readByHandler(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithMaxBodySize(8))
+	shiftapi.HandleRaw(api, "POST /raw", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		_, err := io.ReadAll(r.Body)
+		return err
+	})
+
+	resp := doRequest(t, api, http.MethodPost, "/raw", "0123456789")
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+type TooLargeError struct {
+	Code  string `json:"code"`
+	Limit int64  `json:"limit"`
+}
+
+func TestMaxBodySize_customError(t *testing.T) {
+	api := shiftapi.New(
+		shiftapi.WithMaxBodySize(16),
+		shiftapi.WithRequestTooLargeError(func(err error) *TooLargeError {
+			return &TooLargeError{Code: "TOO_LARGE", Limit: 16}
+		}),
+	)
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequest(t, api, http.MethodPost, "/notes", jsonBody(100))
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", resp.StatusCode)
+	}
+	got := decodeJSON[TooLargeError](t, resp)
+	if got.Code != "TOO_LARGE" || got.Limit != 16 {
+		t.Errorf("unexpected body %+v", got)
+	}
+	if _, ok := api.Spec().Components.Schemas["RequestTooLargeError"].Value.Properties["code"]; !ok {
+		t.Error("expected custom RequestTooLargeError schema")
+	}
+}
+
+func TestMaxBodySize_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /limited", createNote, shiftapi.WithMaxBodySize(1024))
+	shiftapi.Handle(api, "POST /unlimited", createNote)
+	shiftapi.Handle(api, "GET /read", func(r *http.Request, _ struct{}) (*NoteResult, error) {
+		return &NoteResult{}, nil
+	}, shiftapi.WithMaxBodySize(1024))
+
+	spec := api.Spec()
+	if spec.Paths.Find("/limited").Post.Responses.Value("413") == nil {
+		t.Error("expected 413 on limited route")
+	}
+	if spec.Paths.Find("/unlimited").Post.Responses.Value("413") != nil {
+		t.Error("expected no 413 on unlimited route")
+	}
+	if spec.Paths.Find("/read").Get.Responses.Value("413") != nil {
+		t.Error("expected no 413 on route without a body")
+	}
+	if _, ok := spec.Components.Schemas["RequestTooLargeError"]; !ok {
+		t.Error("expected RequestTooLargeError component")
+	}
+}
@@ -0,0 +1,210 @@
+package shiftapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes and decodes request and response bodies for a single media
+// type. Register codecs with [WithCodec]; JSON is registered by default.
+//
+// Codecs for formats outside the standard library (MessagePack, CBOR, …) are
+// small adapters around the format's encoder and decoder:
+//
+//	type CBORCodec struct{}
+//
+//	func (CBORCodec) Decode(r io.Reader, v any) error { return cbor.NewDecoder(r).Decode(v) }
+//	func (CBORCodec) Encode(w io.Writer, v any) error { return cbor.NewEncoder(w).Encode(v) }
+type Codec interface {
+	Decode(r io.Reader, v any) error
+	Encode(w io.Writer, v any) error
+}
+
+// JSONCodec is the default [Codec] for "application/json", backed by
+// [encoding/json].
+type JSONCodec struct{}
+
+func (JSONCodec) Decode(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) }
+func (JSONCodec) Encode(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) }
+
+// XMLCodec is a [Codec] backed by [encoding/xml], typically registered for
+// "application/xml".
+type XMLCodec struct{}
+
+func (XMLCodec) Decode(r io.Reader, v any) error { return xml.NewDecoder(r).Decode(v) }
+func (XMLCodec) Encode(w io.Writer, v any) error { return xml.NewEncoder(w).Encode(v) }
+
+// codecEntry pairs a registered media type with its codec.
+type codecEntry struct {
+	mediaType   string
+	contentType string // Content-Type header value written on responses
+	codec       Codec
+}
+
+func newCodecEntry(mediaType string, codec Codec) codecEntry {
+	contentType := mediaType
+	if isTextMediaType(mediaType) {
+		contentType += "; charset=utf-8"
+	}
+	return codecEntry{mediaType: mediaType, contentType: contentType, codec: codec}
+}
+
+// isTextMediaType reports whether responses of the media type carry a charset.
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+var defaultCodecs = []codecEntry{newCodecEntry("application/json", JSONCodec{})}
+
+// WithCodec registers a [Codec] for the given media type. Request bodies are
+// decoded with the codec matching the request's Content-Type, and response
+// bodies are encoded with the codec selected from the Accept header. Every
+// registered media type is listed in the OpenAPI request and response content
+// maps.
+//
+// JSON ("application/json") is registered by default and remains the
+// fallback for requests without a Content-Type or Accept header. Registering a
+// codec for an already registered media type replaces it.
+//
+// When more than one codec is registered, requests with an unregistered
+// Content-Type are rejected with 415 Unsupported Media Type and requests whose
+// Accept header matches no codec are rejected with 406 Not Acceptable. Error
+// responses are always JSON.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}),
+//	    shiftapi.WithCodec("application/cbor", CBORCodec{}),
+//	)
+func WithCodec(mediaType string, codec Codec) apiOptionFunc {
+	return func(api *API) {
+		entry := newCodecEntry(strings.ToLower(mediaType), codec)
+		for i, c := range api.codecs {
+			if c.mediaType == entry.mediaType {
+				api.codecs[i] = entry
+				return
+			}
+		}
+		api.codecs = append(api.codecs, entry)
+	}
+}
+
+// negotiates reports whether content negotiation is enabled, which is the
+// case once codecs beyond the default are registered.
+func (hc *handlerConfig) negotiates() bool {
+	return len(hc.codecs) > 1
+}
+
+// requestCodec returns the codec for the request's Content-Type. Requests
+// without a Content-Type (or any request when negotiation is disabled) use the
+// default codec. It returns false when the Content-Type has no codec.
+func (hc *handlerConfig) requestCodec(r *http.Request) (codecEntry, bool) {
+	ct := r.Header.Get("Content-Type")
+	if !hc.negotiates() || ct == "" {
+		return hc.codecs[0], true
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return codecEntry{}, false
+	}
+	for _, c := range hc.codecs {
+		if c.mediaType == mediaType {
+			return c, true
+		}
+	}
+	return codecEntry{}, false
+}
+
+// responseCodec selects the response codec from the request's Accept header.
+// The codec with the highest quality value wins; ties go to the codec that
+// was registered first. It returns false when no codec is acceptable.
+func (hc *handlerConfig) responseCodec(r *http.Request) (codecEntry, bool) {
+	accept := r.Header.Get("Accept")
+	if !hc.negotiates() || accept == "" {
+		return hc.codecs[0], true
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := -1, 0.0
+	for i, c := range hc.codecs {
+		if q := acceptQuality(ranges, c.mediaType); q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	if best < 0 {
+		return codecEntry{}, false
+	}
+	return hc.codecs[best], true
+}
+
+// acceptRange is a single media range from an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses an Accept header into its media ranges. Malformed
+// entries are skipped.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for part := range strings.SplitSeq(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality value the Accept ranges assign to the
+// media type, using the most specific matching range (RFC 9110 §12.5.1).
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, ar := range ranges {
+		s := -1
+		switch {
+		case ar.typ == typ && ar.subtype == subtype:
+			s = 2
+		case ar.typ == typ && ar.subtype == "*":
+			s = 1
+		case ar.typ == "*" && ar.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = ar.q, s
+		}
+	}
+	return q
+}
+
+// writeBody encodes v with the codec and writes it with the given status.
+// The body is encoded before the status is written, so that an encoding
+// error is returned while an error response can still be sent.
+func writeBody(w http.ResponseWriter, status int, c codecEntry, v any) error {
+	var buf bytes.Buffer
+	if err := c.codec.Encode(&buf, v); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", c.contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+	return nil
+}
@@ -0,0 +1,206 @@
+package shiftapi_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type CodecGreeting struct {
+	XMLName xml.Name `json:"-" xml:"greeting"`
+	Hello   string   `json:"hello" xml:"hello"`
+}
+
+type CodecPerson struct {
+	XMLName xml.Name `json:"-" xml:"person"`
+	Name    string   `json:"name" xml:"name" validate:"required"`
+}
+
+func TestCodec_defaultsToJSON(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodPost, "/greet", `{"name":"alice"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	if got := decodeJSON[CodecGreeting](t, resp); got.Hello != "alice" {
+		t.Errorf("expected hello alice, got %q", got.Hello)
+	}
+}
+
+func TestCodec_decodesAndEncodesXML(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/greet", `<person><name>bob</name></person>`, map[string]string{
+		"Content-Type": "application/xml",
+		"Accept":       "application/xml",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("expected XML content type, got %q", ct)
+	}
+	if vary := resp.Header.Get("Vary"); vary != "Accept" {
+		t.Errorf("expected Vary: Accept, got %q", vary)
+	}
+	body := readBody(t, resp)
+	if !strings.Contains(body, "<greeting><hello>bob</hello></greeting>") {
+		t.Errorf("unexpected XML body: %s", body)
+	}
+}
+
+func TestCodec_acceptNegotiation(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/xml", "application/xml; charset=utf-8"},
+		{"application/json;q=0.5, application/xml", "application/xml; charset=utf-8"},
+		{"application/xml;q=0.1, */*", "application/json; charset=utf-8"},
+		{"application/*", "application/json; charset=utf-8"},
+		{"text/html, application/xml;q=0.9", "application/xml; charset=utf-8"},
+	}
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			resp := doRequestWithHeaders(t, api, http.MethodPost, "/greet", `{"name":"alice"}`, map[string]string{"Accept": tt.accept})
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, ct)
+			}
+		})
+	}
+}
+
+func TestCodec_notAcceptable(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+	for _, accept := range []string{"text/html", "application/xml;q=0, application/json;q=0"} {
+		resp := doRequestWithHeaders(t, api, http.MethodPost, "/greet", `{"name":"alice"}`, map[string]string{"Accept": accept})
+		if resp.StatusCode != http.StatusNotAcceptable {
+			t.Errorf("Accept %q: expected 406, got %d", accept, resp.StatusCode)
+		}
+	}
+}
+
+func TestCodec_unsupportedMediaType(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/greet", "\xa1", map[string]string{"Content-Type": "application/cbor"})
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestCodec_singleCodecIsLenient(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/greet", `{"name":"alice"}`, map[string]string{
+		"Content-Type": "text/plain",
+		"Accept":       "text/html",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 without negotiation, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestCodec_specListsMediaTypes(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return &CodecGreeting{Hello: in.Name}, nil
+	})
+	op := api.Spec().Paths.Find("/greet").Post
+	for _, mt := range []string{"application/json", "application/xml"} {
+		if op.RequestBody.Value.Content.Get(mt) == nil {
+			t.Errorf("expected request body media type %s", mt)
+		}
+		if op.Responses.Status(http.StatusOK).Value.Content.Get(mt) == nil {
+			t.Errorf("expected response media type %s", mt)
+		}
+	}
+	if op.Responses.Status(http.StatusNotAcceptable) == nil {
+		t.Error("expected 406 response")
+	}
+	if op.Responses.Status(http.StatusUnsupportedMediaType) == nil {
+		t.Error("expected 415 response")
+	}
+}
+
+func TestCodec_specDefaultOmitsNegotiationErrors(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *CodecPerson) (*CodecGreeting, error) {
+		return nil, nil
+	})
+	op := api.Spec().Paths.Find("/greet").Post
+	if op.Responses.Status(http.StatusNotAcceptable) != nil || op.Responses.Status(http.StatusUnsupportedMediaType) != nil {
+		t.Error("406/415 should not be documented when only JSON is registered")
+	}
+}
+
+type CodecReceipt struct {
+	Location string `header:"Location"`
+	ID       string `json:"id" xml:"id"`
+}
+
+type CodecUnencodable struct {
+	Labels map[string]string `json:"labels" xml:"labels"`
+}
+
+func TestCodec_xmlResponseWithHeaderFields(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "GET /receipt", func(r *http.Request, _ struct{}) (*CodecReceipt, error) {
+		return &CodecReceipt{Location: "/receipts/r1", ID: "r1"}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/receipt", "", map[string]string{"Accept": "application/xml"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if got := resp.Header.Get("Location"); got != "/receipts/r1" {
+		t.Errorf("expected Location header, got %q", got)
+	}
+	if body := readBody(t, resp); body != "<CodecReceipt><id>r1</id></CodecReceipt>" {
+		t.Errorf("unexpected XML body %q", body)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/receipt", "")
+	if got := decodeJSON[map[string]any](t, resp); len(got) != 1 || got["id"] != "r1" {
+		t.Errorf("expected only the body fields in JSON, got %v", got)
+	}
+}
+
+func TestCodec_encodeErrorIsNotSentAs200(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}))
+	shiftapi.Handle(api, "GET /labels", func(r *http.Request, _ struct{}) (*CodecUnencodable, error) {
+		return &CodecUnencodable{Labels: map[string]string{"a": "b"}}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/labels", "", map[string]string{"Accept": "application/xml"})
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the body cannot be encoded, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
@@ -0,0 +1,293 @@
+package shiftapi
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Compressor is a response body encoder returned by a [ContentEncoder].
+// Flush writes buffered compressed data to the underlying writer so that
+// streamed responses, such as server-sent events, reach the client promptly.
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// ContentEncoder returns a [Compressor] that writes compressed data to w. See
+// [WithContentEncoder].
+type ContentEncoder func(w io.Writer) (Compressor, error)
+
+// contentEncoderEntry pairs a Content-Encoding token with its encoder.
+type contentEncoderEntry struct {
+	encoding string
+	encoder  ContentEncoder
+}
+
+// defaultContentEncoders returns the response encoders registered by default.
+func defaultContentEncoders() []contentEncoderEntry {
+	return []contentEncoderEntry{
+		{encoding: "gzip", encoder: func(w io.Writer) (Compressor, error) { return gzip.NewWriter(w), nil }},
+	}
+}
+
+// defaultCompressibleTypes are the response media types compressed when
+// [WithResponseCompression] is given no content types.
+var defaultCompressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-ndjson",
+	"application/xml",
+	"application/javascript",
+	"image/svg+xml",
+	"text/*",
+}
+
+// compressionConfig holds the settings of [WithResponseCompression].
+type compressionConfig struct {
+	minSize      int
+	contentTypes []string
+}
+
+// WithResponseCompression compresses response bodies for clients that
+// advertise support in their Accept-Encoding header. gzip is supported by
+// default; register others, such as zstd, with [WithContentEncoder].
+//
+// Bodies smaller than minSize bytes are sent uncompressed, unless the handler
+// flushes first, as [SSEWriter] does after every event. Only responses whose
+// Content-Type matches one of contentTypes are compressed; entries may use a
+// wildcard subtype such as "text/*". With no content types, JSON, XML,
+// JavaScript, SVG, and text responses are compressed.
+//
+// Responses that already set Content-Encoding, HEAD requests, and protocol
+// upgrades such as [HandleWS] connections are passed through untouched.
+//
+// WithResponseCompression returns an [Option] that works at any level; the
+// innermost setting wins:
+//
+//	api := shiftapi.New(shiftapi.WithResponseCompression(1024))
+func WithResponseCompression(minSize int, contentTypes ...string) Option {
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressibleTypes
+	}
+	cfg := &compressionConfig{minSize: minSize, contentTypes: contentTypes}
+	return func(c sharedConfig) {
+		c.setCompression(cfg)
+	}
+}
+
+// WithContentEncoder registers a response body encoder for a Content-Encoding
+// token, used by routes with [WithResponseCompression]. Registered encoders
+// are preferred over the built-in gzip when a client accepts both equally;
+// registering gzip replaces the built-in one.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithContentEncoder("zstd", func(w io.Writer) (shiftapi.Compressor, error) {
+//	        return zstd.NewWriter(w)
+//	    }),
+//	)
+func WithContentEncoder(encoding string, encoder ContentEncoder) apiOptionFunc {
+	return func(api *API) {
+		encoding = strings.ToLower(encoding)
+		i := slices.IndexFunc(api.contentEncoders, func(e contentEncoderEntry) bool { return e.encoding == encoding })
+		if i >= 0 {
+			api.contentEncoders[i].encoder = encoder
+			return
+		}
+		api.contentEncoders = slices.Insert(api.contentEncoders, 0, contentEncoderEntry{encoding: encoding, encoder: encoder})
+	}
+}
+
+// compressHandler wraps h to compress its responses with the encoder the
+// request's Accept-Encoding header selects.
+func compressHandler(h http.Handler, cfg *compressionConfig, encoders []contentEncoderEntry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		enc, ok := negotiateEncoding(r.Header.Get("Accept-Encoding"), encoders)
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, cfg: cfg, enc: enc, status: http.StatusOK}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the encoder with the highest quality value in the
+// Accept-Encoding header, preferring earlier encoders on ties.
+func negotiateEncoding(header string, encoders []contentEncoderEntry) (contentEncoderEntry, bool) {
+	if header == "" {
+		return contentEncoderEntry{}, false
+	}
+	qualities := make(map[string]float64)
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[coding] = q
+	}
+	var best contentEncoderEntry
+	bestQ := 0.0
+	for _, e := range encoders {
+		q, ok := qualities[e.encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressWriter buffers the start of a response until it can decide whether
+// to compress it: once minSize bytes are written, the handler flushes, or the
+// handler returns. It implements Unwrap so that [http.ResponseController]
+// reaches the underlying writer, and FlushError so that flushes pass through
+// the encoder first.
+type compressWriter struct {
+	http.ResponseWriter
+	cfg         *compressionConfig
+	enc         contentEncoderEntry
+	status      int
+	wroteHeader bool
+	started     bool
+	buf         []byte
+	cw          Compressor // non-nil once the response is being compressed
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader || w.started {
+		return
+	}
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.started {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.cfg.minSize {
+			return len(p), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.cw != nil {
+		return w.cw.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// FlushError starts the response if it is still buffered, flushes the
+// encoder, and flushes the underlying writer.
+func (w *compressWriter) FlushError() error {
+	if !w.started {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := w.start(true); err != nil {
+			return err
+		}
+	}
+	if w.cw != nil {
+		if err := w.cw.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the response header, compressing the body if compress is set
+// and the response qualifies, then writes the buffered bytes.
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if compress && w.compressible() {
+		cw, err := w.enc.encoder(w.ResponseWriter)
+		if err == nil {
+			w.cw = cw
+			h.Set("Content-Encoding", w.enc.encoding)
+			h.Del("Content-Length")
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.cw != nil {
+		_, err := w.cw.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible reports whether the buffered response may be compressed.
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	if isNoBodyStatus(w.status) || h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, pattern := range w.cfg.contentTypes {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// close sends a response that is still buffered, uncompressed because it is
+// below the size threshold, and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.started {
+		if !w.wroteHeader {
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.cw != nil {
+		_ = w.cw.Close()
+	}
+}
@@ -0,0 +1,287 @@
+package shiftapi_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/fcjr/shiftapi"
+)
+
+type ReportResult struct {
+	Text string `json:"text"`
+}
+
+func report(n int) func(r *http.Request, _ struct{}) (*ReportResult, error) {
+	return func(r *http.Request, _ struct{}) (*ReportResult, error) {
+		return &ReportResult{Text: strings.Repeat("a", n)}, nil
+	}
+}
+
+func gunzip(t *testing.T, r io.Reader) string {
+	t.Helper()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return string(b)
+}
+
+func TestResponseCompression_gzip(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(256))
+	shiftapi.Handle(api, "GET /report", report(1024))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": "br, gzip;q=0.8"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+	}
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("expected JSON content type, got %q", got)
+	}
+	if body := gunzip(t, resp.Body); !strings.Contains(body, strings.Repeat("a", 1024)) {
+		t.Errorf("unexpected decompressed body %q", body)
+	}
+}
+
+func TestResponseCompression_belowThreshold(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(256))
+	shiftapi.Handle(api, "GET /report", report(10))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": "gzip"})
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected no encoding, got %q", got)
+	}
+	if got := decodeJSON[ReportResult](t, resp); got.Text != strings.Repeat("a", 10) {
+		t.Errorf("unexpected body %+v", got)
+	}
+}
+
+func TestResponseCompression_notAccepted(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(0))
+	shiftapi.Handle(api, "GET /report", report(1024))
+
+	for _, accept := range []string{"", "identity", "gzip;q=0", "br"} {
+		resp := doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": accept})
+		if got := resp.Header.Get("Content-Encoding"); got != "" {
+			t.Errorf("Accept-Encoding %q: expected no encoding, got %q", accept, got)
+		}
+	}
+}
+
+func TestResponseCompression_contentTypeAllowlist(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(0, "text/*"))
+	shiftapi.HandleRaw(api, "GET /image", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		w.Header().Set("Content-Type", "image/png")
+		_, err := w.Write([]byte(strings.Repeat("x", 1024)))
+		return err
+	})
+	shiftapi.HandleRaw(api, "GET /text", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, err := w.Write([]byte(strings.Repeat("x", 1024)))
+		return err
+	})
+	shiftapi.Handle(api, "GET /report", report(1024))
+
+	if got := doRequestWithHeaders(t, api, http.MethodGet, "/image", "", map[string]string{"Accept-Encoding": "gzip"}).Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected image/png to be sent uncompressed, got %q", got)
+	}
+	if got := doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": "gzip"}).Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected JSON outside the allowlist to be sent uncompressed, got %q", got)
+	}
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/text", "", map[string]string{"Accept-Encoding": "gzip"})
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected text/plain to be compressed, got %q", got)
+	}
+	if body := gunzip(t, resp.Body); len(body) != 1024 {
+		t.Errorf("expected 1024 decompressed bytes, got %d", len(body))
+	}
+}
+
+func TestResponseCompression_routeOverridesGroup(t *testing.T) {
+	api := newTestAPI(t)
+	g := api.Group("/v1", shiftapi.WithResponseCompression(4096))
+	shiftapi.Handle(g, "GET /large-threshold", report(1024))
+	shiftapi.Handle(g, "GET /small-threshold", report(1024), shiftapi.WithResponseCompression(64))
+	shiftapi.Handle(api, "GET /plain", report(1024))
+
+	if got := doRequestWithHeaders(t, api, http.MethodGet, "/v1/large-threshold", "", map[string]string{"Accept-Encoding": "gzip"}).Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected group threshold to apply, got %q", got)
+	}
+	if got := doRequestWithHeaders(t, api, http.MethodGet, "/v1/small-threshold", "", map[string]string{"Accept-Encoding": "gzip"}).Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("expected route threshold to apply, got %q", got)
+	}
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/plain", "", map[string]string{"Accept-Encoding": "gzip"})
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected routes outside the group to be uncompressed, got %q", got)
+	}
+	if got := resp.Header.Get("Vary"); got != "" {
+		t.Errorf("expected no Vary header, got %q", got)
+	}
+}
+
+func TestResponseCompression_errorResponses(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(0))
+	shiftapi.Handle(api, "GET /fail", func(r *http.Request, _ struct{}) (*ReportResult, error) {
+		return nil, io.ErrUnexpectedEOF
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/fail", "", map[string]string{"Accept-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if body := gunzip(t, resp.Body); !strings.Contains(body, "internal server error") {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestResponseCompression_noContent(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(0))
+	shiftapi.Handle(api, "DELETE /items/{id}", func(r *http.Request, _ struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, shiftapi.WithStatus(http.StatusNoContent))
+
+	resp := doRequestWithHeaders(t, api, http.MethodDelete, "/items/1", "", map[string]string{"Accept-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no encoding, got %q", got)
+	}
+	if body := readBody(t, resp); body != "" {
+		t.Errorf("expected empty body, got %q", body)
+	}
+}
+
+func TestResponseCompression_SSEFlushesEachEvent(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(1024))
+	release := make(chan struct{})
+	shiftapi.HandleSSE(api, "GET /events", func(r *http.Request, _ struct{}, sse *shiftapi.SSEWriter) error {
+		if err := sse.Send(sseMessage{Text: "hello"}); err != nil {
+			return err
+		}
+		<-release
+		return sse.Send(sseMessage{Text: "world"})
+	}, shiftapi.SSESends(
+		shiftapi.SSEEventType[sseMessage]("message"),
+	))
+
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	defer close(release)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}, Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+
+	// The first event must be readable while the handler is still blocked.
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(zr)
+	line, err := br.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "event: message\n" {
+		t.Errorf("unexpected first line %q", line)
+	}
+}
+
+func TestResponseCompression_skipsWebSocketUpgrade(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithResponseCompression(0))
+	shiftapi.HandleWS(api, "GET /ws",
+		shiftapi.Websocket(noSetup,
+			shiftapi.WSSends(shiftapi.WSMessageType[wsServerMsg]("server")),
+			shiftapi.WSOn("msg", func(sender *shiftapi.WSSender, _ struct{}, msg wsClientMsg) error {
+				return sender.Send(wsServerMsg{Text: msg.Text})
+			}),
+		),
+	)
+
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	ctx := context.Background()
+	conn, _, err := websocket.Dial(ctx, srv.URL+"/ws", &websocket.DialOptions{
+		HTTPHeader: http.Header{"Accept-Encoding": {"gzip"}},
+	})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow() //nolint:errcheck
+
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "msg", "data": map[string]any{"text": "hi"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var envelope struct {
+		Data wsServerMsg `json:"data"`
+	}
+	if err := wsjson.Read(ctx, conn, &envelope); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if envelope.Data.Text != "hi" {
+		t.Errorf("got %q, want %q", envelope.Data.Text, "hi")
+	}
+	conn.Close(websocket.StatusNormalClosure, "") //nolint:errcheck
+}
+
+type upperCompressor struct{ w io.Writer }
+
+func (c upperCompressor) Write(p []byte) (int, error) {
+	return c.w.Write([]byte(strings.ToUpper(string(p))))
+}
+func (c upperCompressor) Flush() error { return nil }
+func (c upperCompressor) Close() error { return nil }
+
+func TestWithContentEncoder(t *testing.T) {
+	api := shiftapi.New(
+		shiftapi.WithResponseCompression(0),
+		shiftapi.WithContentEncoder("x-upper", func(w io.Writer) (shiftapi.Compressor, error) {
+			return upperCompressor{w: w}, nil
+		}),
+	)
+	shiftapi.Handle(api, "GET /report", report(3))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": "gzip, x-upper"})
+	if got := resp.Header.Get("Content-Encoding"); got != "x-upper" {
+		t.Fatalf("expected registered encoder to be preferred, got %q", got)
+	}
+	if body := readBody(t, resp); !strings.Contains(body, `"TEXT":"AAA"`) {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/report", "", map[string]string{"Accept-Encoding": "gzip"})
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("expected gzip fallback, got %q", got)
+	}
+}
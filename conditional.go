@@ -0,0 +1,298 @@
+package shiftapi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ETagger is implemented by response values that carry an entity tag. The
+// tag is sent as the ETag header; it may be given with or without quotes, and
+// a W/ prefix marks it as weak. A header-tagged ETag field works the same way.
+//
+// GET and HEAD requests whose If-None-Match header matches the tag are
+// answered with 304 Not Modified instead of a 2xx response body.
+type ETagger interface {
+	ETag() string
+}
+
+// LastModifier is implemented by response values that know when they last
+// changed. The time is sent as the Last-Modified header. A header-tagged
+// Last-Modified string field works the same way.
+//
+// GET and HEAD requests whose If-Modified-Since header is not before that
+// time are answered with 304 Not Modified instead of a 2xx response body,
+// unless they carry If-None-Match.
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// ErrPreconditionFailed is returned by [CheckPreconditions], and by routes
+// using [WithPreconditions], when a request's
+// If-Match, If-None-Match, or If-Unmodified-Since header does not hold. It is
+// sent as 412 Precondition Failed.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// preconditionLookup returns the current validators of the resource an
+// unsafe request targets.
+type preconditionLookup func(r *http.Request) (etag string, lastModified time.Time, err error)
+
+// WithPreconditions makes an unsafe route enforce the If-Match,
+// If-None-Match, and If-Unmodified-Since headers before the handler runs.
+// lookup returns the current validators of the resource the request targets,
+// as described for [CheckPreconditions]; when the headers do not hold, the
+// request is answered with 412 Precondition Failed. An error from lookup is
+// sent like a handler error. The route documents the conditional headers and
+// the 412 response. Registration panics on GET and HEAD routes, whose
+// validators come from their responses.
+//
+//	shiftapi.Handle(api, "PUT /items/{id}", updateItem,
+//	    shiftapi.WithPreconditions(func(r *http.Request) (string, time.Time, error) {
+//	        item, err := store.Get(r.Context(), r.PathValue("id"))
+//	        if err != nil {
+//	            return "", time.Time{}, err
+//	        }
+//	        return item.ETag(), item.UpdatedAt, nil
+//	    }),
+//	)
+func WithPreconditions(lookup func(r *http.Request) (etag string, lastModified time.Time, err error)) routeOptionFunc {
+	return func(cfg *routeConfig) {
+		cfg.preconditions = lookup
+	}
+}
+
+// CheckPreconditions evaluates the If-Match, If-None-Match, and
+// If-Unmodified-Since headers of an unsafe request against the current
+// validators of the resource, before it is changed. It returns
+// [ErrPreconditionFailed] when they do not hold, which a handler can return
+// as is to respond with 412. Pass an empty etag or zero lastModified when the
+// resource has no such validator, or both when it does not exist.
+//
+// Routes using [WithPreconditions] run this check before the handler and
+// document it; use CheckPreconditions directly when the handler needs to
+// evaluate the headers itself, for example inside a transaction.
+func CheckPreconditions(r *http.Request, etag string, lastModified time.Time) error {
+	etag = quoteETag(etag)
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !matchETag(im, etag, false) {
+			return ErrPreconditionFailed
+		}
+	} else if ius, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(ius) {
+			return ErrPreconditionFailed
+		}
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, etag, true) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// requirePreconditions wraps h so that it runs only when the request's
+// conditional headers hold for the validators returned by lookup.
+func requirePreconditions(h http.Handler, lookup preconditionLookup, hc *handlerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag, lastModified, err := lookup(r)
+		if err == nil {
+			err = CheckPreconditions(r, etag, lastModified)
+		}
+		if err != nil {
+			for _, sh := range hc.staticHeaders {
+				w.Header().Set(sh.name, sh.value)
+			}
+			handleError(w, hc, err)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// isSafeMethod reports whether conditional requests to the method are
+// answered from the response's validators rather than checked beforehand.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// setValidatorHeaders sets the ETag and Last-Modified headers from a response
+// implementing [ETagger] or [LastModifier], unless they are already set. A
+// response returned by value is checked through a pointer to a copy, so that
+// methods with pointer receivers count, as they do in responseValidators.
+func setValidatorHeaders(h http.Header, resp any) {
+	if v := reflect.ValueOf(resp); v.IsValid() && v.Kind() != reflect.Pointer {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		resp = p.Interface()
+	}
+	if et, ok := resp.(ETagger); ok && h.Get("ETag") == "" {
+		if tag := quoteETag(et.ETag()); tag != "" {
+			h.Set("ETag", tag)
+		}
+	}
+	if lm, ok := resp.(LastModifier); ok && h.Get("Last-Modified") == "" {
+		if t := lm.LastModified(); !t.IsZero() {
+			h.Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// notModified reports whether a GET or HEAD request's conditional headers
+// match the validators already set on the response.
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return matchETag(inm, h.Get("ETag"), true)
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.After(ims)
+}
+
+// quoteETag returns the tag as a quoted entity tag, keeping a W/ prefix.
+func quoteETag(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	opaque, weak := strings.CutPrefix(tag, "W/")
+	if !strings.HasPrefix(opaque, `"`) {
+		opaque = `"` + opaque + `"`
+	}
+	if weak {
+		return "W/" + opaque
+	}
+	return opaque
+}
+
+// matchETag reports whether an If-Match or If-None-Match header value matches
+// the current entity tag, using weak or strong comparison (RFC 9110 §8.8.3.2).
+// A "*" matches any existing tag.
+func matchETag(header, current string, weak bool) bool {
+	if current == "" {
+		return false
+	}
+	currentOpaque, currentWeak := strings.CutPrefix(current, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidateOpaque, candidateWeak := strings.CutPrefix(candidate, "W/")
+		if candidateOpaque != currentOpaque {
+			continue
+		}
+		if weak || (!candidateWeak && !currentWeak) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	etaggerType      = reflect.TypeFor[ETagger]()
+	lastModifierType = reflect.TypeFor[LastModifier]()
+)
+
+// responseValidators reports whether values of t carry an entity tag and a
+// modification time, through [ETagger] and [LastModifier] or header-tagged
+// fields.
+func responseValidators(t reflect.Type) (etag, lastModified bool) {
+	if t == nil {
+		return false, false
+	}
+	etag = t.Implements(etaggerType) || reflect.PointerTo(t).Implements(etaggerType)
+	lastModified = t.Implements(lastModifierType) || reflect.PointerTo(t).Implements(lastModifierType)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return etag, lastModified
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasHeaderTag(f) {
+			continue
+		}
+		switch http.CanonicalHeaderKey(headerFieldName(f)) {
+		case "Etag":
+			etag = true
+		case "Last-Modified":
+			lastModified = true
+		}
+	}
+	return etag, lastModified
+}
+
+// validatorHeaders documents the ETag and Last-Modified response headers that
+// values of t set through [ETagger] and [LastModifier]. Header-tagged fields
+// are documented with the other response headers.
+func validatorHeaders(t reflect.Type) openapi3.Headers {
+	if t == nil {
+		return nil
+	}
+	headers := make(openapi3.Headers)
+	if t.Implements(etaggerType) || reflect.PointerTo(t).Implements(etaggerType) {
+		headers["Etag"] = stringHeaderRef("Etag")
+	}
+	if t.Implements(lastModifierType) || reflect.PointerTo(t).Implements(lastModifierType) {
+		headers["Last-Modified"] = stringHeaderRef("Last-Modified")
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+func stringHeaderRef(name string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Name: name,
+				In:   "header",
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{Type: &openapi3.Types{"string"}},
+				},
+			},
+		},
+	}
+}
+
+// conditionalParams documents the conditional request headers an operation
+// honors, given the validators its responses carry.
+func conditionalParams(method string, etag, lastModified bool) openapi3.Parameters {
+	var names []string
+	if isSafeMethod(method) {
+		if etag {
+			names = append(names, "If-None-Match")
+		}
+		if lastModified {
+			names = append(names, "If-Modified-Since")
+		}
+	} else {
+		if etag {
+			names = append(names, "If-Match", "If-None-Match")
+		}
+		if lastModified {
+			names = append(names, "If-Unmodified-Since")
+		}
+	}
+	params := make(openapi3.Parameters, 0, len(names))
+	for _, name := range names {
+		params = append(params, &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name: name,
+				In:   "header",
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{Type: &openapi3.Types{"string"}},
+				},
+			},
+		})
+	}
+	return params
+}
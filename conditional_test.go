@@ -0,0 +1,281 @@
+package shiftapi_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fcjr/shiftapi"
+)
+
+var articleUpdated = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type Article struct {
+	ID      string    `json:"id"`
+	Version int       `json:"version"`
+	Updated time.Time `json:"updated"`
+}
+
+func (a *Article) ETag() string            { return fmt.Sprintf("%s-%d", a.ID, a.Version) }
+func (a *Article) LastModified() time.Time { return a.Updated }
+
+type ArticleUpdate struct {
+	Title string `json:"title"`
+}
+
+func currentArticle(r *http.Request) (string, time.Time, error) {
+	if r.PathValue("id") == "missing" {
+		return "", time.Time{}, &NotFoundError{Message: "no such article"}
+	}
+	current := &Article{ID: r.PathValue("id"), Version: 1, Updated: articleUpdated}
+	return current.ETag(), current.Updated, nil
+}
+
+func TestConditional_validatorHeaders(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /articles/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 1, Updated: articleUpdated}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/articles/a", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got != `"a-1"` {
+		t.Errorf("expected quoted ETag, got %q", got)
+	}
+	if got := resp.Header.Get("Last-Modified"); got != "Sun, 01 Mar 2026 12:00:00 GMT" {
+		t.Errorf("unexpected Last-Modified %q", got)
+	}
+}
+
+func TestConditional_ifNoneMatch(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /articles/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 1, Updated: articleUpdated}, nil
+	})
+
+	for _, inm := range []string{`"a-1"`, `W/"a-1"`, `"x", "a-1"`, "*"} {
+		resp := doRequestWithHeaders(t, api, http.MethodGet, "/articles/a", "", map[string]string{"If-None-Match": inm})
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-None-Match %s: expected 304, got %d", inm, resp.StatusCode)
+			continue
+		}
+		if got := resp.Header.Get("ETag"); got != `"a-1"` {
+			t.Errorf("expected ETag on 304, got %q", got)
+		}
+		if got := readBody(t, resp); got != "" {
+			t.Errorf("expected empty body, got %q", got)
+		}
+	}
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/articles/a", "", map[string]string{
+		"If-None-Match":     `"stale"`,
+		"If-Modified-Since": "Mon, 02 Mar 2026 00:00:00 GMT",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected If-None-Match to take precedence, got %d", resp.StatusCode)
+	}
+}
+
+func TestConditional_notModifiedForAny2xx(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /drafts/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 1}, nil
+	}, shiftapi.WithStatus(http.StatusNonAuthoritativeInfo))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/drafts/d", "", map[string]string{"If-None-Match": `"d-1"`})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", resp.StatusCode)
+	}
+}
+
+func TestConditional_ifModifiedSince(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /articles/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 1, Updated: articleUpdated}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/articles/a", "", map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 12:00:00 GMT"})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", resp.StatusCode)
+	}
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/articles/a", "", map[string]string{"If-Modified-Since": "Sat, 28 Feb 2026 00:00:00 GMT"})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestConditional_ifMatch(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "PUT /articles/{id}", func(r *http.Request, in ArticleUpdate) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 2, Updated: articleUpdated.Add(time.Hour)}, nil
+	}, shiftapi.WithPreconditions(currentArticle))
+
+	tests := []struct {
+		headers map[string]string
+		want    int
+	}{
+		{map[string]string{"If-Match": `"a-1"`}, http.StatusOK},
+		{map[string]string{"If-Match": "*"}, http.StatusOK},
+		{map[string]string{"If-Match": `"a-0"`}, http.StatusPreconditionFailed},
+		{map[string]string{"If-Match": `W/"a-1"`}, http.StatusPreconditionFailed},
+		{map[string]string{"If-None-Match": "*"}, http.StatusPreconditionFailed},
+		{map[string]string{"If-Unmodified-Since": "Sun, 01 Mar 2026 12:00:00 GMT"}, http.StatusOK},
+		{map[string]string{"If-Unmodified-Since": "Sun, 01 Mar 2026 11:00:00 GMT"}, http.StatusPreconditionFailed},
+		{nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := doRequestWithHeaders(t, api, http.MethodPut, "/articles/a", `{"title":"x"}`, tt.headers)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%v: expected %d, got %d: %s", tt.headers, tt.want, resp.StatusCode, readBody(t, resp))
+		}
+	}
+
+	resp := doRequestWithHeaders(t, api, http.MethodPut, "/articles/a", `{"title":"x"}`, map[string]string{"If-Match": `"a-0"`})
+	if body := decodeJSON[map[string]string](t, resp); body["message"] != "precondition failed" {
+		t.Errorf("unexpected message %q", body["message"])
+	}
+}
+
+func TestConditional_preconditionsSkipHandler(t *testing.T) {
+	called := false
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "PUT /articles/{id}", func(r *http.Request, in ArticleUpdate) (*Article, error) {
+		called = true
+		return &Article{ID: r.PathValue("id")}, nil
+	}, shiftapi.WithPreconditions(currentArticle), shiftapi.WithError[*NotFoundError](http.StatusNotFound))
+
+	resp := doRequestWithHeaders(t, api, http.MethodPut, "/articles/a", `{"title":"x"}`, map[string]string{"If-Match": `"a-0"`})
+	if resp.StatusCode != http.StatusPreconditionFailed || called {
+		t.Errorf("expected 412 without calling the handler, got %d (called=%v)", resp.StatusCode, called)
+	}
+	resp = doRequestWithHeaders(t, api, http.MethodPut, "/articles/missing", `{"title":"x"}`, map[string]string{"If-Match": "*"})
+	if resp.StatusCode != http.StatusNotFound || called {
+		t.Errorf("expected lookup error as 404 without calling the handler, got %d (called=%v)", resp.StatusCode, called)
+	}
+}
+
+func TestConditional_preconditionsOnSafeMethodPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	shiftapi.Handle(newTestAPI(t), "GET /articles/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return nil, nil
+	}, shiftapi.WithPreconditions(currentArticle))
+}
+
+func TestConditional_checkPreconditions(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPut, "/articles/a", nil)
+	r.Header.Set("If-Match", `"a-0"`)
+	if err := shiftapi.CheckPreconditions(r, `"a-1"`, time.Time{}); !errors.Is(err, shiftapi.ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	r.Header.Set("If-Match", `"a-1"`)
+	if err := shiftapi.CheckPreconditions(r, `"a-1"`, time.Time{}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+type TaggedArticle struct {
+	ID   string `json:"id"`
+	ETag string `header:"ETag"`
+}
+
+func TestConditional_headerTaggedETag(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /tagged", func(r *http.Request, _ struct{}) (*TaggedArticle, error) {
+		return &TaggedArticle{ID: "t", ETag: `"v7"`}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/tagged", "", map[string]string{"If-None-Match": `"v7"`})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", resp.StatusCode)
+	}
+}
+
+func TestConditional_noValidatorsUnaffected(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /plain", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/plain", "", map[string]string{"If-None-Match": "*"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	op := api.Spec().Paths.Find("/plain").Get
+	if op.Responses.Value("304") != nil || len(op.Parameters) != 0 {
+		t.Error("expected no conditional documentation")
+	}
+}
+
+func TestConditional_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /articles/{id}", func(r *http.Request, _ struct{}) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 1, Updated: articleUpdated}, nil
+	})
+	shiftapi.Handle(api, "PUT /articles/{id}", func(r *http.Request, in ArticleUpdate) (*Article, error) {
+		return &Article{ID: r.PathValue("id"), Version: 2, Updated: articleUpdated.Add(time.Hour)}, nil
+	}, shiftapi.WithPreconditions(currentArticle))
+	spec := api.Spec()
+
+	get := spec.Paths.Find("/articles/{id}").Get
+	if get.Responses.Value("304") == nil {
+		t.Error("expected 304 on GET")
+	}
+	if get.Parameters.GetByInAndName("header", "If-None-Match") == nil || get.Parameters.GetByInAndName("header", "If-Modified-Since") == nil {
+		t.Error("expected If-None-Match and If-Modified-Since on GET")
+	}
+	ok := get.Responses.Value("200").Value
+	if ok.Headers["Etag"] == nil || ok.Headers["Last-Modified"] == nil {
+		t.Errorf("expected ETag and Last-Modified response headers, got %v", ok.Headers)
+	}
+
+	put := spec.Paths.Find("/articles/{id}").Put
+	if put.Responses.Value("412") == nil {
+		t.Error("expected 412 on PUT")
+	}
+	if put.Parameters.GetByInAndName("header", "If-Match") == nil || put.Parameters.GetByInAndName("header", "If-Unmodified-Since") == nil {
+		t.Error("expected If-Match and If-Unmodified-Since on PUT")
+	}
+	if put.Responses.Value("304") != nil {
+		t.Error("expected no 304 on PUT")
+	}
+}
+
+func TestConditional_specWithoutPreconditions(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "PUT /articles/{id}", func(r *http.Request, in ArticleUpdate) (*Article, error) {
+		return &Article{ID: r.PathValue("id")}, nil
+	})
+	put := api.Spec().Paths.Find("/articles/{id}").Put
+	if put.Responses.Value("412") != nil || put.Parameters.GetByInAndName("header", "If-Match") != nil {
+		t.Error("expected no precondition documentation without WithPreconditions")
+	}
+}
+
+func TestConditional_pointerReceiverOnValueResponse(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /articles/{id}", func(r *http.Request, _ struct{}) (Article, error) {
+		return Article{ID: r.PathValue("id"), Version: 3, Updated: articleUpdated}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/articles/a", "")
+	if got := resp.Header.Get("ETag"); got != `"a-3"` {
+		t.Fatalf("expected ETag from the pointer method, got %q", got)
+	}
+	if got := resp.Header.Get("Last-Modified"); got == "" {
+		t.Error("expected Last-Modified from the pointer method")
+	}
+
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/articles/a", "", map[string]string{"If-None-Match": `"a-3"`})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,214 @@
+package shiftapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// hasCookieTag returns true if the struct field has a `cookie` tag.
+func hasCookieTag(f reflect.StructField) bool {
+	return f.Tag.Get("cookie") != ""
+}
+
+// cookieFieldName returns the cookie name for a struct field. Cookie names
+// are case-sensitive, so the tag value is used verbatim.
+func cookieFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("cookie"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// resetCookieFields zeros out any cookie-tagged fields on a struct value.
+// This is called after body decode so that cookie-tagged fields are only
+// populated by parseCookiesInto, not by JSON keys that happen to match.
+func resetCookieFields(rv reflect.Value) {
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if f.IsExported() && hasCookieTag(f) {
+			rv.Field(i).SetZero()
+		}
+	}
+}
+
+// parseCookiesInto populates cookie-tagged fields on an existing struct value
+// from the request's cookies. Non-cookie fields are left untouched.
+// Scalar types, pointer-to-scalar types, and types implementing
+// [encoding.TextUnmarshaler] are supported (no slices).
+func parseCookiesInto(rv reflect.Value, r *http.Request, scalars scalarRegistry) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	rt := rv.Type()
+	if rt.Kind() != reflect.Struct {
+		return fmt.Errorf("cookie type must be a struct, got %s", rt.Kind())
+	}
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() || !hasCookieTag(field) {
+			continue
+		}
+
+		name := cookieFieldName(field)
+		raw := field.Tag.Get("default")
+		c, err := r.Cookie(name)
+		if err == nil && c.Value != "" {
+			raw = c.Value
+		} else if err != nil && !errors.Is(err, http.ErrNoCookie) {
+			return &cookieParseError{Field: name, Err: err}
+		}
+		if raw == "" {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		// Handle pointer fields (optional cookies)
+		if field.Type.Kind() == reflect.Pointer {
+			ptr := reflect.New(field.Type.Elem())
+			if err := scalars.setValue(ptr.Elem(), raw); err != nil {
+				return &cookieParseError{Field: name, Err: err}
+			}
+			fv.Set(ptr)
+			continue
+		}
+
+		// Handle scalar fields
+		if err := scalars.setValue(fv, raw); err != nil {
+			return &cookieParseError{Field: name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// cookieParseError is returned when a cookie value cannot be parsed.
+type cookieParseError struct {
+	Field string
+	Err   error
+}
+
+func (e *cookieParseError) Error() string {
+	return fmt.Sprintf("invalid cookie %q: %v", e.Field, e.Err)
+}
+
+func (e *cookieParseError) Unwrap() error { return e.Err }
+
+var httpCookieType = reflect.TypeFor[http.Cookie]()
+
+// cookieAttributes builds a cookie template from the options in a response
+// field's cookie tag, e.g. `cookie:"session,path=/,maxage=3600,secure,httponly,samesite=lax"`.
+// The returned cookie has its Name set; the Value is filled in per response.
+func cookieAttributes(f reflect.StructField) (*http.Cookie, error) {
+	c := &http.Cookie{Name: cookieFieldName(f)}
+	_, opts, _ := strings.Cut(f.Tag.Get("cookie"), ",")
+	if opts == "" {
+		return c, nil
+	}
+	for opt := range strings.SplitSeq(opts, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch strings.ToLower(key) {
+		case "path":
+			c.Path = val
+		case "domain":
+			c.Domain = val
+		case "maxage":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maxage %q", val)
+			}
+			c.MaxAge = n
+		case "secure":
+			c.Secure = true
+		case "httponly":
+			c.HttpOnly = true
+		case "partitioned":
+			c.Partitioned = true
+		case "samesite":
+			switch strings.ToLower(val) {
+			case "lax":
+				c.SameSite = http.SameSiteLaxMode
+			case "strict":
+				c.SameSite = http.SameSiteStrictMode
+			case "none":
+				c.SameSite = http.SameSiteNoneMode
+			default:
+				return nil, fmt.Errorf("invalid samesite %q", val)
+			}
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown cookie option %q", key)
+		}
+	}
+	return c, nil
+}
+
+// validateRespCookieFields checks that cookie-tagged fields on a response type
+// are scalars, pointers to scalars, or http.Cookie values, and that their tag
+// options parse. Called at registration time.
+func validateRespCookieFields(t reflect.Type, scalars scalarRegistry) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasCookieTag(f) {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft != httpCookieType && !scalars.isTextScalar(ft) && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map || ft.Kind() == reflect.Struct) {
+			panic(fmt.Sprintf("shiftapi: cookie-tagged response field %q must be a scalar or http.Cookie, got %s", f.Name, f.Type))
+		}
+		if _, err := cookieAttributes(f); err != nil {
+			panic(fmt.Sprintf("shiftapi: cookie-tagged response field %q: %v", f.Name, err))
+		}
+	}
+}
+
+// responseCookie builds the cookie to send for a cookie-tagged response
+// field. It returns nil when the field is a nil pointer. http.Cookie fields
+// are sent as-is, with the tag name used when the cookie has no Name.
+func responseCookie(f reflect.StructField, fv reflect.Value, scalars scalarRegistry) *http.Cookie {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	if fv.Type() == httpCookieType {
+		c := fv.Interface().(http.Cookie)
+		if c.Name == "" {
+			c.Name = cookieFieldName(f)
+		}
+		return &c
+	}
+	c, err := cookieAttributes(f)
+	if err != nil {
+		// Tag options are validated at registration time.
+		return nil
+	}
+	c.Value = scalars.format(fv)
+	return c
+}
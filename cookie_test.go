@@ -0,0 +1,272 @@
+package shiftapi_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type SessionID string
+
+func (s *SessionID) UnmarshalText(b []byte) error {
+	if !strings.HasPrefix(string(b), "sess_") {
+		return fmt.Errorf("missing sess_ prefix")
+	}
+	*s = SessionID(b)
+	return nil
+}
+
+type CookieInput struct {
+	Session SessionID `cookie:"session" validate:"required"`
+	Theme   *string   `cookie:"theme"`
+	Visits  int       `cookie:"visits"`
+}
+
+type CookieResult struct {
+	Session string `json:"session"`
+	Theme   string `json:"theme"`
+	Visits  int    `json:"visits"`
+}
+
+type CookieWithBodyInput struct {
+	Session string `cookie:"session"`
+	Name    string `json:"name"`
+}
+
+func cookieHandler(r *http.Request, in CookieInput) (*CookieResult, error) {
+	res := &CookieResult{Session: string(in.Session), Visits: in.Visits}
+	if in.Theme != nil {
+		res.Theme = *in.Theme
+	}
+	return res, nil
+}
+
+func TestCookie_parsesTypedFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Cookie": "session=sess_abc; theme=dark; visits=3"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[CookieResult](t, resp)
+	if got.Session != "sess_abc" || got.Theme != "dark" || got.Visits != 3 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestCookie_optionalPointerOmitted(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Cookie": "session=sess_abc"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[CookieResult](t, resp)
+	if got.Theme != "" {
+		t.Errorf("expected empty theme, got %q", got.Theme)
+	}
+}
+
+func TestCookie_missingRequiredReturns422(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	resp := doRequest(t, api, http.MethodGet, "/me", "")
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestCookie_textUnmarshalerErrorReturns400(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Cookie": "session=nope"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestCookie_invalidScalarReturns400(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Cookie": "session=sess_abc; visits=many"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestCookie_withBodyIgnoresJSONKey(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /items", func(r *http.Request, in CookieWithBodyInput) (*CookieResult, error) {
+		return &CookieResult{Session: in.Session, Theme: in.Name}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/items", `{"name":"widget","Session":"from-body"}`, map[string]string{"Cookie": "session=from-cookie"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[CookieResult](t, resp)
+	if got.Session != "from-cookie" {
+		t.Errorf("expected session from cookie, got %q", got.Session)
+	}
+	if got.Theme != "widget" {
+		t.Errorf("expected name %q, got %q", "widget", got.Theme)
+	}
+}
+
+func TestCookie_specParameters(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", cookieHandler)
+
+	op := api.Spec().Paths.Find("/me").Get
+	params := map[string]bool{}
+	for _, p := range op.Parameters {
+		if p.Value.In != "cookie" {
+			continue
+		}
+		params[p.Value.Name] = p.Value.Required
+	}
+	if len(params) != 3 {
+		t.Fatalf("expected 3 cookie parameters, got %v", params)
+	}
+	if !params["session"] {
+		t.Error("expected session cookie to be required")
+	}
+	if params["theme"] {
+		t.Error("expected theme cookie to be optional")
+	}
+	for _, p := range op.Parameters {
+		if p.Value.Name == "visits" && !p.Value.Schema.Value.Type.Is("integer") {
+			t.Errorf("expected visits schema type integer, got %v", p.Value.Schema.Value.Type)
+		}
+	}
+}
+
+func TestCookie_specStrippedFromBody(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /items", func(r *http.Request, in CookieWithBodyInput) (*Status, error) {
+		return &Status{OK: true}, nil
+	})
+
+	schema := api.Spec().Components.Schemas["CookieWithBodyInput"]
+	if schema == nil {
+		t.Fatal("expected CookieWithBodyInput schema")
+	}
+	if _, ok := schema.Value.Properties["Session"]; ok {
+		t.Error("cookie field should not appear in body schema")
+	}
+	if _, ok := schema.Value.Properties["name"]; !ok {
+		t.Error("expected name in body schema")
+	}
+}
+
+type LoginResult struct {
+	Session  string       `cookie:"session,path=/,maxage=3600,httponly,secure,samesite=lax"`
+	Theme    *string      `cookie:"theme"`
+	Tracking *http.Cookie `cookie:"tracking"`
+	User     string       `json:"user"`
+}
+
+func findCookie(resp *http.Response, name string) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestResponseCookie_setsCookies(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /login", func(r *http.Request, _ struct{}) (*LoginResult, error) {
+		return &LoginResult{
+			Session:  "sess_abc",
+			Tracking: &http.Cookie{Value: "t1", Path: "/track"},
+			User:     "alice",
+		}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodPost, "/login", "{}")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	session := findCookie(resp, "session")
+	if session == nil {
+		t.Fatal("expected session cookie")
+	}
+	if session.Value != "sess_abc" || session.Path != "/" || session.MaxAge != 3600 {
+		t.Errorf("unexpected session cookie: %+v", session)
+	}
+	if !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteLaxMode {
+		t.Errorf("expected session cookie attributes from tag, got %+v", session)
+	}
+	if findCookie(resp, "theme") != nil {
+		t.Error("nil pointer cookie should not be set")
+	}
+	tracking := findCookie(resp, "tracking")
+	if tracking == nil || tracking.Value != "t1" || tracking.Path != "/track" {
+		t.Errorf("unexpected tracking cookie: %+v", tracking)
+	}
+
+	body := readBody(t, resp)
+	if strings.Contains(body, "sess_abc") || strings.Contains(body, "Session") {
+		t.Errorf("cookie fields should be stripped from body, got %s", body)
+	}
+	if !strings.Contains(body, `"user":"alice"`) {
+		t.Errorf("expected user in body, got %s", body)
+	}
+}
+
+func TestResponseCookie_specDocumentsSetCookie(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /login", func(r *http.Request, _ struct{}) (*LoginResult, error) {
+		return &LoginResult{}, nil
+	})
+
+	resp := api.Spec().Paths.Find("/login").Post.Responses.Status(http.StatusOK)
+	h, ok := resp.Value.Headers["Set-Cookie"]
+	if !ok {
+		t.Fatal("expected Set-Cookie response header")
+	}
+	if !h.Value.Required {
+		t.Error("expected Set-Cookie to be required when a non-pointer cookie field exists")
+	}
+	for _, name := range []string{"session", "theme", "tracking"} {
+		if !strings.Contains(h.Value.Description, name) {
+			t.Errorf("expected description to mention %q, got %q", name, h.Value.Description)
+		}
+	}
+
+	schema := api.Spec().Components.Schemas["LoginResult"]
+	if schema == nil {
+		t.Fatal("expected LoginResult schema")
+	}
+	for _, name := range []string{"Session", "Theme", "Tracking"} {
+		if _, ok := schema.Value.Properties[name]; ok {
+			t.Errorf("cookie field %q should not appear in body schema", name)
+		}
+	}
+}
+
+func TestResponseCookie_invalidTagPanics(t *testing.T) {
+	type BadCookie struct {
+		Session string `cookie:"session,samesite=sometimes"`
+	}
+	api := newTestAPI(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid samesite option")
+		}
+	}()
+	shiftapi.Handle(api, "GET /bad", func(r *http.Request, _ struct{}) (*BadCookie, error) {
+		return &BadCookie{}, nil
+	})
+}
@@ -0,0 +1,194 @@
+package shiftapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// decodeMode holds the JSON body decoding flags set by [WithStrictDecoding]
+// and [WithUseNumber]. Flags accumulate from the API, group, and route levels.
+type decodeMode uint8
+
+const (
+	decodeStrict decodeMode = 1 << iota
+	decodeUseNumber
+)
+
+// WithStrictDecoding rejects JSON request bodies that contain keys not
+// declared on the input type, or data after the first JSON value. Keys of
+// path, query, header, and cookie fields count as undeclared, since the body
+// schema leaves those fields out. Both return
+// 400 Bad Request; an unknown key is reported to the bad request handler as an
+// [*UnknownFieldError] naming the key. Request body schemas of strict routes
+// are documented with additionalProperties: false, as a separate component
+// named with a Strict suffix so that other routes using the type keep theirs.
+//
+// WithStrictDecoding returns an [Option] that works at any level:
+//
+//	api := shiftapi.New(shiftapi.WithStrictDecoding())
+//	v2 := api.Group("/api/v2", shiftapi.WithStrictDecoding())
+//	shiftapi.Handle(api, "POST /users", createUser, shiftapi.WithStrictDecoding())
+//
+// It applies to the built-in [JSONCodec]; other codecs decode as usual.
+func WithStrictDecoding() Option {
+	return func(c sharedConfig) {
+		c.addDecodeMode(decodeStrict)
+	}
+}
+
+// WithUseNumber decodes JSON numbers in interface-typed request body fields
+// as [json.Number] instead of float64, preserving their precision. Like
+// [WithStrictDecoding], it works at any level and applies to the built-in
+// [JSONCodec].
+func WithUseNumber() Option {
+	return func(c sharedConfig) {
+		c.addDecodeMode(decodeUseNumber)
+	}
+}
+
+// UnknownFieldError is passed to the bad request handler when a route with
+// [WithStrictDecoding] receives a JSON body key that the input type does not
+// declare. The default 400 response includes its message.
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// errTrailingData is returned when a strict route's body has data after the
+// first JSON value.
+var errTrailingData = errors.New("unexpected data after JSON body")
+
+// decodeRequestBody decodes a request body with the codec. When the codec is
+// the built-in JSONCodec, the route's decode mode is applied, and in strict
+// mode a top-level key matching one of paramKeys is reported as unknown.
+func decodeRequestBody(codec Codec, r io.Reader, v any, mode decodeMode, paramKeys []string) error {
+	if _, ok := codec.(JSONCodec); !ok || mode == 0 {
+		return codec.Decode(r, v)
+	}
+	if mode&decodeStrict != 0 && len(paramKeys) > 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := rejectParamKeys(data, paramKeys); err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	dec := json.NewDecoder(r)
+	if mode&decodeStrict != 0 {
+		dec.DisallowUnknownFields()
+	}
+	if mode&decodeUseNumber != 0 {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		// encoding/json reports unknown keys only through the message.
+		if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if name, uerr := strconv.Unquote(quoted); uerr == nil {
+				return &UnknownFieldError{Field: name}
+			}
+		}
+		return err
+	}
+	if mode&decodeStrict != 0 {
+		if _, err := dec.Token(); err != io.EOF {
+			return errTrailingData
+		}
+	}
+	return nil
+}
+
+// rejectParamKeys returns an [*UnknownFieldError] for the first key of the JSON
+// object in data that names a parameter field. Keys match case-insensitively,
+// as encoding/json matches them. Data that is not an object is left to the
+// decoder to report.
+func rejectParamKeys(data []byte, paramKeys []string) error {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(data, &obj) != nil {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(obj)) {
+		for _, pk := range paramKeys {
+			if strings.EqualFold(key, pk) {
+				return &UnknownFieldError{Field: key}
+			}
+		}
+	}
+	return nil
+}
+
+// paramFieldKeys returns the JSON keys of t's path, query, header, and cookie
+// fields, which are bound from the request rather than the body.
+func paramFieldKeys(t reflect.Type) []string {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []string
+	for f := range t.Fields() {
+		if f.IsExported() && isRequestParamField(f) {
+			if name := jsonFieldName(f); name != "-" {
+				keys = append(keys, name)
+			}
+		}
+	}
+	return keys
+}
+
+// cloneObjectSchemas copies s along with the nested property and item schemas
+// that closeObjectSchemas modifies, so the copy can be closed without changing
+// s or the schemas generated for other routes.
+func cloneObjectSchemas(s *openapi3.Schema) *openapi3.Schema {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Properties != nil {
+		c.Properties = make(openapi3.Schemas, len(s.Properties))
+		for name, p := range s.Properties {
+			c.Properties[name] = cloneSchemaRef(p)
+		}
+	}
+	c.Items = cloneSchemaRef(s.Items)
+	return &c
+}
+
+func cloneSchemaRef(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	c := *ref
+	c.Value = cloneObjectSchemas(ref.Value)
+	return &c
+}
+
+// closeObjectSchemas sets additionalProperties: false on an object schema and
+// the object schemas nested in its properties and array items. Map schemas,
+// which declare their own additionalProperties, are left unchanged.
+func closeObjectSchemas(s *openapi3.Schema) {
+	if s == nil {
+		return
+	}
+	if s.Type.Is("object") && s.AdditionalProperties.Schema == nil {
+		s.AdditionalProperties = openapi3.AdditionalProperties{Has: new(false)}
+	}
+	for _, p := range s.Properties {
+		closeObjectSchemas(p.Value)
+	}
+	if s.Items != nil {
+		closeObjectSchemas(s.Items.Value)
+	}
+}
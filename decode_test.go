@@ -0,0 +1,186 @@
+package shiftapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type StrictInput struct {
+	Name    string         `json:"name"`
+	Address *StrictAddress `json:"address,omitempty"`
+	Extra   map[string]any `json:"extra,omitempty"`
+}
+
+type StrictAddress struct {
+	City string `json:"city"`
+}
+
+type StrictResult struct {
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+}
+
+func strictHandler(r *http.Request, in StrictInput) (*StrictResult, error) {
+	res := &StrictResult{Name: in.Name}
+	if n, ok := in.Extra["amount"].(json.Number); ok {
+		res.Amount = n.String()
+	}
+	return res, nil
+}
+
+func TestStrictDecoding_rejectsUnknownField(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /strict", strictHandler, shiftapi.WithStrictDecoding())
+
+	resp := doRequest(t, api, http.MethodPost, "/strict", `{"name":"a","nmae":"typo"}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	body := decodeJSON[map[string]string](t, resp)
+	if body["message"] != `unknown field "nmae"` {
+		t.Errorf("expected message naming the field, got %q", body["message"])
+	}
+}
+
+func TestStrictDecoding_rejectsParameterFieldKeys(t *testing.T) {
+	type Input struct {
+		Page  int    `query:"page"`
+		Token string `header:"X-Token" json:"token"`
+		Name  string `json:"name"`
+	}
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /strict", func(r *http.Request, in Input) (*StrictResult, error) {
+		return &StrictResult{Name: in.Name}, nil
+	}, shiftapi.WithStrictDecoding())
+
+	for body, field := range map[string]string{
+		`{"name":"a","page":2}`:    "page",
+		`{"name":"a","token":"t"}`: "token",
+		`{"Page":2,"name":"a"}`:    "Page",
+	} {
+		resp := doRequest(t, api, http.MethodPost, "/strict?page=1", body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", body, resp.StatusCode, readBody(t, resp))
+		}
+		msg := decodeJSON[map[string]string](t, resp)["message"]
+		if want := `unknown field "` + field + `"`; msg != want {
+			t.Errorf("%s: expected message %q, got %q", body, want, msg)
+		}
+	}
+
+	resp := doRequest(t, api, http.MethodPost, "/strict?page=1", `{"name":"a"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestStrictDecoding_rejectsTrailingData(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /strict", strictHandler, shiftapi.WithStrictDecoding())
+
+	resp := doRequest(t, api, http.MethodPost, "/strict", `{"name":"a"} {"name":"b"}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doRequest(t, api, http.MethodPost, "/strict", "{\"name\":\"a\"}\n  ")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected trailing whitespace to be allowed, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestStrictDecoding_offByDefault(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /lenient", strictHandler)
+
+	resp := doRequest(t, api, http.MethodPost, "/lenient", `{"name":"a","nmae":"typo"} trailing`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestStrictDecoding_inheritedFromGroup(t *testing.T) {
+	api := newTestAPI(t)
+	v2 := api.Group("/v2", shiftapi.WithStrictDecoding())
+	shiftapi.Handle(v2, "POST /strict", strictHandler)
+
+	resp := doRequest(t, api, http.MethodPost, "/v2/strict", `{"address":{"city":"x","zip":"1"}}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown nested field, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /numbers", strictHandler, shiftapi.WithUseNumber())
+
+	resp := doRequest(t, api, http.MethodPost, "/numbers", `{"name":"a","extra":{"amount":9007199254740993}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[StrictResult](t, resp)
+	if got.Amount != "9007199254740993" {
+		t.Errorf("expected precise amount, got %q", got.Amount)
+	}
+}
+
+func TestStrictDecoding_specDisallowsAdditionalProperties(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /strict", strictHandler, shiftapi.WithStrictDecoding())
+
+	schema := api.Spec().Components.Schemas["StrictInputStrict"].Value
+	if schema.AdditionalProperties.Has == nil || *schema.AdditionalProperties.Has {
+		t.Errorf("expected additionalProperties: false, got %+v", schema.AdditionalProperties)
+	}
+	if extra := schema.Properties["extra"].Value; extra.AdditionalProperties.Has != nil && !*extra.AdditionalProperties.Has {
+		t.Error("map fields should keep their additionalProperties")
+	}
+}
+
+func TestStrictDecoding_specUnchangedByDefault(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /lenient", strictHandler)
+
+	schema := api.Spec().Components.Schemas["StrictInput"].Value
+	if schema.AdditionalProperties.Has != nil {
+		t.Errorf("expected additionalProperties unset, got %v", *schema.AdditionalProperties.Has)
+	}
+}
+
+func TestStrictDecoding_specLeavesSharedSchemaOpen(t *testing.T) {
+	lenient := func(r *http.Request, in StrictInput) (*StrictInput, error) { return &in, nil }
+	for _, strictFirst := range []bool{false, true} {
+		api := newTestAPI(t)
+		if strictFirst {
+			shiftapi.Handle(api, "POST /items", strictHandler, shiftapi.WithStrictDecoding())
+			shiftapi.Handle(api, "PUT /items", lenient)
+		} else {
+			shiftapi.Handle(api, "PUT /items", lenient)
+			shiftapi.Handle(api, "POST /items", strictHandler, shiftapi.WithStrictDecoding())
+		}
+		spec := api.Spec()
+
+		shared := spec.Components.Schemas["StrictInput"].Value
+		if shared.AdditionalProperties.Has != nil {
+			t.Errorf("strictFirst=%v: shared schema closed", strictFirst)
+		}
+		if addr := shared.Properties["address"].Value; addr.AdditionalProperties.Has != nil {
+			t.Errorf("strictFirst=%v: shared nested schema closed", strictFirst)
+		}
+		strict := spec.Components.Schemas["StrictInputStrict"].Value
+		if strict.AdditionalProperties.Has == nil || *strict.AdditionalProperties.Has {
+			t.Errorf("strictFirst=%v: strict schema not closed", strictFirst)
+		}
+
+		item := spec.Paths.Find("/items")
+		if got := item.Put.RequestBody.Value.Content.Get("application/json").Schema.Ref; got != "#/components/schemas/StrictInput" {
+			t.Errorf("strictFirst=%v: PUT body refs %q", strictFirst, got)
+		}
+		if got := item.Post.RequestBody.Value.Content.Get("application/json").Schema.Ref; got != "#/components/schemas/StrictInputStrict" {
+			t.Errorf("strictFirst=%v: POST body refs %q", strictFirst, got)
+		}
+	}
+}
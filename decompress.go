@@ -0,0 +1,153 @@
+package shiftapi
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ContentDecoder returns a reader that decompresses r. See [WithContentDecoder].
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// defaultContentDecoders returns the request body decoders registered by
+// default: gzip and deflate (zlib), from the standard library.
+func defaultContentDecoders() map[string]ContentDecoder {
+	return map[string]ContentDecoder{
+		"gzip":    func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) },
+	}
+}
+
+// WithRequestDecompression decodes request bodies according to their
+// Content-Encoding header before they are bound, so clients can send
+// compressed JSON, form, or raw bodies. gzip and deflate are supported by
+// default; register others, such as zstd, with [WithContentDecoder].
+// Decompressed bodies larger than maxDecompressedSize are rejected with 413,
+// guarding against decompression bombs. Requests with an unsupported
+// Content-Encoding are rejected with 415. Affected operations document the
+// accepted encodings as a Content-Encoding header parameter.
+//
+// [WithMaxBodySize] still applies to the compressed body as received.
+//
+// WithRequestDecompression returns an [Option] that works at any level:
+//
+//	api := shiftapi.New(shiftapi.WithRequestDecompression(32 << 20))
+func WithRequestDecompression(maxDecompressedSize int64) Option {
+	return func(c sharedConfig) {
+		c.setMaxDecompressedSize(maxDecompressedSize)
+	}
+}
+
+// WithContentDecoder registers a request body decoder for a Content-Encoding
+// token, used by routes with [WithRequestDecompression]. Registering a decoder
+// for gzip or deflate replaces the built-in one. The returned reader is closed
+// when the handler returns, so decoders that hold resources can release them.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithContentDecoder("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//	        d, err := zstd.NewReader(r)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return d.IOReadCloser(), nil
+//	    }),
+//	)
+func WithContentDecoder(encoding string, decoder ContentDecoder) apiOptionFunc {
+	return func(api *API) {
+		api.contentDecoders[strings.ToLower(encoding)] = decoder
+	}
+}
+
+// decompressBody replaces r.Body with its decoded form when the request has a
+// Content-Encoding, limiting the decoded size to limit. The encoding header is
+// removed so that handlers see a plain body. It returns false if an encoding
+// is not registered.
+func decompressBody(w http.ResponseWriter, r *http.Request, decoders map[string]ContentDecoder, limit int64) (bool, error) {
+	header := r.Header.Get("Content-Encoding")
+	if header == "" {
+		return true, nil
+	}
+	var encodings []string
+	for enc := range strings.SplitSeq(header, ",") {
+		enc = strings.ToLower(strings.TrimSpace(enc))
+		if enc == "" || enc == "identity" {
+			continue
+		}
+		if _, ok := decoders[enc]; !ok {
+			return false, nil
+		}
+		encodings = append(encodings, enc)
+	}
+	// Encodings are listed in the order they were applied.
+	var reader io.Reader = r.Body
+	closers := []io.Closer{r.Body}
+	for _, enc := range slices.Backward(encodings) {
+		dec, err := decoders[enc](reader)
+		if err != nil {
+			_ = (&decodedBody{closers: closers[1:]}).Close()
+			return true, err
+		}
+		reader = dec
+		closers = slices.Insert(closers, 0, io.Closer(dec))
+	}
+	r.Body = http.MaxBytesReader(w, &decodedBody{Reader: reader, closers: closers}, limit)
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+	return true, nil
+}
+
+// decodedBody is a decompressed request body. Closing it closes each decoder,
+// outermost first, and then the body as received.
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+	closed  bool
+}
+
+func (b *decodedBody) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	var errs []error
+	for _, c := range b.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// closeRequestBody closes the request body as it is when the handler returns.
+// The server only closes the body it created, not the decoders that
+// decompressBody puts in front of it.
+func closeRequestBody(r *http.Request) {
+	_ = r.Body.Close()
+}
+
+// contentEncodingParam documents the accepted request Content-Encoding values
+// of an operation.
+func contentEncodingParam(decoders map[string]ContentDecoder) *openapi3.ParameterRef {
+	encodings := []any{"identity"}
+	for _, enc := range slices.Sorted(maps.Keys(decoders)) {
+		encodings = append(encodings, enc)
+	}
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        "Content-Encoding",
+			In:          "header",
+			Description: "Compression applied to the request body.",
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+					Enum: encodings,
+				},
+			},
+		},
+	}
+}
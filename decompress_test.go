@@ -0,0 +1,228 @@
+package shiftapi_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRequestDecompression_gzip(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1 << 20))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", gzipString(t, jsonBody(10)), map[string]string{"Content-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if got := decodeJSON[NoteResult](t, resp); got.Length != 10 {
+		t.Errorf("expected length 10, got %d", got.Length)
+	}
+}
+
+func TestRequestDecompression_deflate(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1 << 20))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write([]byte(jsonBody(5)))
+	_ = zw.Close()
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", buf.String(), map[string]string{"Content-Encoding": "Deflate"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if got := decodeJSON[NoteResult](t, resp); got.Length != 5 {
+		t.Errorf("expected length 5, got %d", got.Length)
+	}
+}
+
+func TestRequestDecompression_identity(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1 << 20))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", jsonBody(3), map[string]string{"Content-Encoding": "identity"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestRequestDecompression_unsupportedEncoding(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1 << 20))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", "whatever", map[string]string{"Content-Encoding": "br"})
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	body := decodeJSON[map[string]string](t, resp)
+	if body["message"] != "unsupported content encoding" {
+		t.Errorf("unexpected message %q", body["message"])
+	}
+}
+
+func TestRequestDecompression_bomb(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1024))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	compressed := gzipString(t, jsonBody(1<<20))
+	if len(compressed) > 4096 {
+		t.Fatalf("test payload should compress well, got %d bytes", len(compressed))
+	}
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", compressed, map[string]string{"Content-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestRequestDecompression_corruptBody(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithRequestDecompression(1 << 20))
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", jsonBody(3), map[string]string{"Content-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestRequestDecompression_offByDefault(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /notes", createNote)
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/notes", gzipString(t, jsonBody(3)), map[string]string{"Content-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for undecoded body, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestRequestDecompression_rawHandlerSeesPlainBody(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.HandleRaw(api, "POST /raw", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Error("expected Content-Encoding to be removed")
+		}
+		_, err = w.Write(b)
+		return err
+	}, shiftapi.WithRequestDecompression(1<<20))
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/raw", gzipString(t, "hello"), map[string]string{"Content-Encoding": "gzip"})
+	if got := readBody(t, resp); got != "hello" {
+		t.Errorf("expected decoded body, got %q", got)
+	}
+}
+
+func TestWithContentDecoder(t *testing.T) {
+	api := shiftapi.New(
+		shiftapi.WithRequestDecompression(1<<20),
+		shiftapi.WithContentDecoder("X-Upper", func(r io.Reader) (io.ReadCloser, error) {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(strings.NewReader(strings.ToLower(string(b)))), nil
+		}),
+	)
+	shiftapi.HandleRaw(api, "POST /raw", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		_, err := io.Copy(w, r.Body)
+		return err
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/raw", "HELLO", map[string]string{"Content-Encoding": "x-upper"})
+	if got := readBody(t, resp); got != "hello" {
+		t.Errorf("expected custom decoder output, got %q", got)
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed *int
+}
+
+func (c closeRecorder) Close() error {
+	*c.closed++
+	return nil
+}
+
+func TestRequestDecompression_closesDecoders(t *testing.T) {
+	closed := 0
+	api := shiftapi.New(
+		shiftapi.WithRequestDecompression(1<<20),
+		shiftapi.WithContentDecoder("gzip", func(r io.Reader) (io.ReadCloser, error) {
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return closeRecorder{Reader: zr, closed: &closed}, nil
+		}),
+	)
+	shiftapi.Handle(api, "POST /greet", func(r *http.Request, in *Person) (*Person, error) {
+		return in, nil
+	})
+	shiftapi.HandleRaw(api, "POST /raw", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		_, err := io.Copy(w, r.Body)
+		return err
+	})
+
+	for _, path := range []string{"/greet", "/raw"} {
+		closed = 0
+		resp := doRequestWithHeaders(t, api, http.MethodPost, path, gzipString(t, gzipString(t, `{"name":"ada"}`)), map[string]string{"Content-Encoding": "gzip, gzip"})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", path, resp.StatusCode, readBody(t, resp))
+		}
+		if closed != 2 {
+			t.Errorf("%s: expected both decoders closed, got %d", path, closed)
+		}
+	}
+}
+
+func TestRequestDecompression_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /compressed", createNote, shiftapi.WithRequestDecompression(1<<20))
+	shiftapi.Handle(api, "POST /plain", createNote)
+
+	op := api.Spec().Paths.Find("/compressed").Post
+	var param *openapi3.Parameter
+	for _, p := range op.Parameters {
+		if p.Value.Name == "Content-Encoding" {
+			param = p.Value
+		}
+	}
+	if param == nil || param.In != "header" {
+		t.Fatalf("expected Content-Encoding header parameter, got %+v", op.Parameters)
+	}
+	if got := param.Schema.Value.Enum; len(got) != 3 || got[0] != "identity" || got[1] != "deflate" || got[2] != "gzip" {
+		t.Errorf("unexpected encodings %v", got)
+	}
+	if op.Responses.Value("415") == nil || op.Responses.Value("413") == nil {
+		t.Error("expected 413 and 415 responses")
+	}
+
+	plain := api.Spec().Paths.Find("/plain").Post
+	if len(plain.Parameters) != 0 || plain.Responses.Value("415") != nil {
+		t.Error("expected plain route to be undocumented")
+	}
+}
@@ -0,0 +1,114 @@
+package shiftapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultValues returns the raw values declared by a field's `default` tag,
+// or nil when the field has none. Slice defaults are comma-separated
+// (default:"a,b").
+func defaultValues(f reflect.StructField) []string {
+	def, ok := f.Tag.Lookup("default")
+	if !ok {
+		return nil
+	}
+	return splitDefault(f.Type, def)
+}
+
+// splitDefault splits a raw default into the values bound to a field of type t.
+func splitDefault(t reflect.Type, raw string) []string {
+	if t.Kind() == reflect.Slice {
+		return strings.Split(raw, ",")
+	}
+	return []string{raw}
+}
+
+// applyBodyDefaults sets default-tagged body fields on a struct value before
+// the request body is decoded, so that keys missing from the payload keep
+// their defaults while keys that are present overwrite them. Nested structs
+// are handled recursively.
+func applyBodyDefaults(rv reflect.Value, scalars scalarRegistry) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() || hasPathTag(f) || hasQueryTag(f) || hasHeaderTag(f) || hasCookieTag(f) || hasFormTag(f) {
+			continue
+		}
+		if jsonFieldName(f) == "-" {
+			continue
+		}
+		if values := defaultValues(f); values != nil {
+			if err := setQueryValues(rv.Field(i), values, scalars); err != nil {
+				return fmt.Errorf("invalid default for field %q: %w", f.Name, err)
+			}
+			continue
+		}
+		if f.Type.Kind() == reflect.Struct && !scalars.isTextScalar(f.Type) {
+			if err := applyBodyDefaults(rv.Field(i), scalars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasBodyDefaults reports whether any body field of t (including nested
+// structs) declares a `default` tag. Used at registration time so that
+// routes without defaults skip applyBodyDefaults entirely.
+func hasBodyDefaults(t reflect.Type, scalars scalarRegistry) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() || hasPathTag(f) || hasQueryTag(f) || hasHeaderTag(f) || hasCookieTag(f) || hasFormTag(f) {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("default"); ok {
+			return true
+		}
+		if f.Type.Kind() == reflect.Struct && !scalars.isTextScalar(f.Type) && hasBodyDefaults(f.Type, scalars) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaTagValue parses a `default` or `example` tag value for type t and
+// returns it in the form documented in the OpenAPI schema. Text scalars keep
+// their raw string form. It returns an error when the value does not parse,
+// which surfaces as a registration-time panic.
+func (s scalarRegistry) schemaTagValue(t reflect.Type, tag, raw string) (any, error) {
+	values := splitDefault(t, raw)
+	v := reflect.New(t).Elem()
+	if err := setQueryValues(v, values, s); err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", tag, raw, err)
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	elem := t
+	for elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+	if s.isTextScalar(elem) {
+		if v.Kind() == reflect.Slice {
+			return values, nil
+		}
+		return values[0], nil
+	}
+	return v.Interface(), nil
+}
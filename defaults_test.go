@@ -0,0 +1,230 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type DefaultsQueryInput struct {
+	Limit  int           `query:"limit" default:"20" validate:"max=100"`
+	Sort   *string       `query:"sort" default:"created"`
+	Tags   []string      `query:"tag" default:"a,b"`
+	Window time.Duration `query:"window"`
+	Since  time.Time     `query:"since" default:"2024-01-01T00:00:00Z"`
+	Lang   string        `header:"Accept-Language" default:"en"`
+	Theme  string        `cookie:"theme" default:"light"`
+}
+
+type DefaultsQueryResult struct {
+	Limit int      `json:"limit"`
+	Sort  string   `json:"sort"`
+	Tags  []string `json:"tags"`
+	Since string   `json:"since"`
+	Lang  string   `json:"lang"`
+	Theme string   `json:"theme"`
+}
+
+func defaultsHandler(r *http.Request, in DefaultsQueryInput) (*DefaultsQueryResult, error) {
+	res := &DefaultsQueryResult{
+		Limit: in.Limit,
+		Tags:  in.Tags,
+		Since: in.Since.Format(time.RFC3339),
+		Lang:  in.Lang,
+		Theme: in.Theme,
+	}
+	if in.Sort != nil {
+		res.Sort = *in.Sort
+	}
+	return res, nil
+}
+
+func TestDefaults_appliedWhenAbsent(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /items", defaultsHandler)
+
+	resp := doRequest(t, api, http.MethodGet, "/items", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[DefaultsQueryResult](t, resp)
+	if got.Limit != 20 || got.Sort != "created" || !slices.Equal(got.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected query defaults: %+v", got)
+	}
+	if got.Since != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected since default: %q", got.Since)
+	}
+	if got.Lang != "en" || got.Theme != "light" {
+		t.Errorf("unexpected header/cookie defaults: %+v", got)
+	}
+}
+
+func TestDefaults_overriddenWhenPresent(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /items", defaultsHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/items?limit=5&sort=name&tag=x", nil)
+	req.Header.Set("Accept-Language", "fr")
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[DefaultsQueryResult](t, resp)
+	if got.Limit != 5 || got.Sort != "name" || !slices.Equal(got.Tags, []string{"x"}) {
+		t.Errorf("unexpected query values: %+v", got)
+	}
+	if got.Lang != "fr" || got.Theme != "dark" {
+		t.Errorf("unexpected header/cookie values: %+v", got)
+	}
+}
+
+func TestDefaults_validatedAfterBinding(t *testing.T) {
+	type In struct {
+		Limit int `query:"limit" default:"500" validate:"max=100"`
+	}
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /items", func(r *http.Request, in In) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/items", "")
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for out-of-range default, got %d", resp.StatusCode)
+	}
+}
+
+type DefaultsBody struct {
+	Name     string   `json:"name"`
+	Priority int      `json:"priority" default:"3"`
+	Labels   []string `json:"labels" default:"triage"`
+	Options  struct {
+		Notify bool `json:"notify" default:"true"`
+	} `json:"options"`
+}
+
+func TestDefaults_jsonBodyMissingFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /tickets", func(r *http.Request, in DefaultsBody) (*DefaultsBody, error) {
+		return &in, nil
+	})
+
+	resp := doRequest(t, api, http.MethodPost, "/tickets", `{"name":"bug"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[DefaultsBody](t, resp)
+	if got.Priority != 3 || !slices.Equal(got.Labels, []string{"triage"}) || !got.Options.Notify {
+		t.Errorf("expected body defaults, got %+v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodPost, "/tickets", `{"name":"bug","priority":1,"labels":[],"options":{"notify":false}}`)
+	got = decodeJSON[DefaultsBody](t, resp)
+	if got.Priority != 1 || len(got.Labels) != 0 || got.Options.Notify {
+		t.Errorf("expected payload to override defaults, got %+v", got)
+	}
+}
+
+func TestDefaults_formField(t *testing.T) {
+	type In struct {
+		Name string `form:"name"`
+		Role string `form:"role" default:"member"`
+	}
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /members", func(r *http.Request, in In) (*In, error) {
+		return &in, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/members", strings.NewReader(url.Values{"name": {"a"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	got := decodeJSON[In](t, rec.Result())
+	if got.Role != "member" {
+		t.Errorf("expected form default, got %q", got.Role)
+	}
+}
+
+func TestDefaults_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /items", defaultsHandler)
+	shiftapi.Handle(api, "POST /tickets", func(r *http.Request, in DefaultsBody) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	defaults := map[string]any{}
+	for _, p := range api.Spec().Paths.Find("/items").Get.Parameters {
+		defaults[p.Value.Name] = p.Value.Schema.Value.Default
+	}
+	if defaults["limit"] != 20 {
+		t.Errorf("expected limit default 20, got %#v", defaults["limit"])
+	}
+	if defaults["sort"] != "created" {
+		t.Errorf("expected sort default, got %#v", defaults["sort"])
+	}
+	if tags, ok := defaults["tag"].([]string); !ok || !slices.Equal(tags, []string{"a", "b"}) {
+		t.Errorf("expected tag default [a b], got %#v", defaults["tag"])
+	}
+	if defaults["since"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("expected since default as string, got %#v", defaults["since"])
+	}
+	if defaults["Accept-Language"] != "en" || defaults["theme"] != "light" {
+		t.Errorf("expected header/cookie defaults, got %#v / %#v", defaults["Accept-Language"], defaults["theme"])
+	}
+	if defaults["window"] != nil {
+		t.Errorf("expected no default for window, got %#v", defaults["window"])
+	}
+
+	body := api.Spec().Components.Schemas["DefaultsBody"].Value
+	if body.Properties["priority"].Value.Default != 3 {
+		t.Errorf("expected priority default 3, got %#v", body.Properties["priority"].Value.Default)
+	}
+	if slices.Contains(body.Required, "priority") {
+		t.Error("fields with defaults should not be required")
+	}
+	if !slices.Contains(body.Required, "name") {
+		t.Error("expected name to remain required")
+	}
+}
+
+func TestDefaults_invalidDefaultPanics(t *testing.T) {
+	type In struct {
+		Limit int `query:"limit" default:"twenty"`
+	}
+	api := newTestAPI(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unparseable default")
+		}
+	}()
+	shiftapi.Handle(api, "GET /items", func(r *http.Request, in In) (*Empty, error) {
+		return &Empty{}, nil
+	})
+}
+
+func TestDefaults_invalidFormDefaultPanics(t *testing.T) {
+	type In struct {
+		Count int `form:"count" default:"abc"`
+	}
+	api := newTestAPI(t)
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected panic for unparseable form default")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "count") {
+			t.Errorf("expected the panic to name the field, got %v", r)
+		}
+	}()
+	shiftapi.Handle(api, "POST /items", func(r *http.Request, in In) (*Empty, error) {
+		return &Empty{}, nil
+	})
+}
@@ -0,0 +1,65 @@
+package shiftapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// Deprecation marks a route as deprecated. Set it on [RouteInfo] to document
+// the operation as deprecated and to send the Deprecation, Sunset, and Link
+// headers on every response:
+//
+//	shiftapi.Handle(api, "GET /v1/users", listUsersV1, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
+//	    Summary: "List users",
+//	    Deprecation: &shiftapi.Deprecation{
+//	        Date:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+//	        Sunset: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+//	        Link:   "/v2/users",
+//	    },
+//	}))
+type Deprecation struct {
+	// Date is when the route was or will be deprecated, sent as the
+	// Deprecation header (RFC 9745), e.g. "@1772323200". Required: routes
+	// whose Deprecation has no Date panic at registration.
+	Date time.Time
+	// Sunset is when the route will stop responding, sent as the Sunset
+	// header (RFC 8594) and documented as x-sunset. Zero if not yet known.
+	Sunset time.Time
+	// Link is the URL of the replacement, sent as a Link header with the
+	// successor-version relation. Empty if there is none.
+	Link string
+}
+
+// headers returns the response headers that announce the deprecation. It
+// panics if the deprecation has no date. Called at registration time.
+func (d *Deprecation) headers() []staticResponseHeader {
+	if d.Date.IsZero() {
+		panic("shiftapi: Deprecation requires a Date")
+	}
+	headers := []staticResponseHeader{{name: "Deprecation", value: fmt.Sprintf("@%d", d.Date.Unix())}}
+	if !d.Sunset.IsZero() {
+		headers = append(headers, staticResponseHeader{name: "Sunset", value: d.Sunset.UTC().Format(http.TimeFormat)})
+	}
+	if d.Link != "" {
+		headers = append(headers, staticResponseHeader{name: "Link", value: fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Link)})
+	}
+	return headers
+}
+
+// announceDeprecation wraps h so that every response of a deprecated route,
+// including error responses, carries the deprecation headers.
+func announceDeprecation(h http.Handler, headers []staticResponseHeader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, sh := range headers {
+			w.Header().Set(sh.name, sh.value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// isDeprecated reports whether a struct field is tagged deprecated:"true".
+func isDeprecated(tag reflect.StructTag) bool {
+	return tag.Get("deprecated") == "true"
+}
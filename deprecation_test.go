@@ -0,0 +1,177 @@
+package shiftapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fcjr/shiftapi"
+)
+
+var usersV1Sunset = time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type LegacyUser struct {
+	Name     string `json:"name"`
+	Nickname string `json:"nickname" deprecated:"true"`
+}
+
+type LegacyOrder struct {
+	SKU string `json:"sku" validate:"required"`
+}
+
+type LegacyUserQuery struct {
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset" deprecated:"true"`
+	Client string `header:"X-Client" deprecated:"true"`
+}
+
+func TestDeprecation_headers(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /v1/users", func(r *http.Request, _ LegacyUserQuery) (*LegacyUser, error) {
+		return &LegacyUser{Name: "ada"}, nil
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
+		Summary: "List users",
+		Deprecation: &shiftapi.Deprecation{
+			Date:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: usersV1Sunset,
+			Link:   "/v2/users",
+		},
+	}))
+
+	resp := doRequest(t, api, http.MethodGet, "/v1/users", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	want := map[string]string{
+		"Deprecation": "@1772323200",
+		"Sunset":      "Thu, 31 Dec 2026 00:00:00 GMT",
+		"Link":        `</v2/users>; rel="successor-version"`,
+	}
+	for name, value := range want {
+		if got := resp.Header.Get(name); got != value {
+			t.Errorf("%s: expected %q, got %q", name, value, got)
+		}
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/v1/users?limit=x", "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	for name, value := range want {
+		if got := resp.Header.Get(name); got != value {
+			t.Errorf("400 %s: expected %q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestDeprecation_headersOnErrorResponses(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /v1/orders/{id}", func(r *http.Request, _ struct{}) (*LegacyUser, error) {
+		return nil, errors.New("boom")
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
+		Deprecation: &shiftapi.Deprecation{Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}))
+	shiftapi.Handle(api, "POST /v1/orders", func(r *http.Request, in *LegacyOrder) (*LegacyUser, error) {
+		return &LegacyUser{}, nil
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
+		Deprecation: &shiftapi.Deprecation{Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}))
+
+	for _, tt := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/v1/orders/1", "", http.StatusInternalServerError},
+		{http.MethodPost, "/v1/orders", `{"sku":""}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/v1/orders", `{`, http.StatusBadRequest},
+	} {
+		resp := doRequest(t, api, tt.method, tt.path, tt.body)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.status, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Deprecation"); got != "@1772323200" {
+			t.Errorf("%s %s: expected the Deprecation header on a %d, got %q", tt.method, tt.path, resp.StatusCode, got)
+		}
+	}
+}
+
+func TestDeprecation_undatedPanics(t *testing.T) {
+	api := newTestAPI(t)
+	defer func() {
+		if r := recover(); r != "shiftapi: Deprecation requires a Date" {
+			t.Fatalf("expected panic for a Deprecation without a Date, got %v", r)
+		}
+	}()
+	shiftapi.HandleRaw(api, "GET /old", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{Deprecation: &shiftapi.Deprecation{}}))
+}
+
+func TestDeprecation_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /v1/users", func(r *http.Request, _ LegacyUserQuery) (*LegacyUser, error) {
+		return &LegacyUser{Name: "ada"}, nil
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
+		Summary: "List users",
+		Deprecation: &shiftapi.Deprecation{
+			Date:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: usersV1Sunset,
+			Link:   "/v2/users",
+		},
+	}))
+	spec := api.Spec()
+
+	op := spec.Paths.Find("/v1/users").Get
+	if !op.Deprecated {
+		t.Error("expected operation to be deprecated")
+	}
+	if got := op.Extensions["x-sunset"]; got != "2026-12-31T00:00:00Z" {
+		t.Errorf("unexpected x-sunset %v", got)
+	}
+	if op.Parameters.GetByInAndName("query", "limit").Deprecated {
+		t.Error("expected limit not to be deprecated")
+	}
+	if !op.Parameters.GetByInAndName("query", "offset").Deprecated {
+		t.Error("expected offset to be deprecated")
+	}
+	if !op.Parameters.GetByInAndName("header", "X-Client").Deprecated {
+		t.Error("expected X-Client to be deprecated")
+	}
+
+	user := spec.Components.Schemas["LegacyUser"].Value
+	if user.Properties["name"].Value.Deprecated || !user.Properties["nickname"].Value.Deprecated {
+		t.Errorf("expected only nickname to be deprecated")
+	}
+}
+
+func TestDeprecation_asyncAPIChannel(t *testing.T) {
+	api := shiftapi.New()
+	shiftapi.HandleWS(api, "GET /ws",
+		shiftapi.Websocket(
+			noSetup,
+			shiftapi.WSSends(shiftapi.WSMessageType[wsServerMsg]("server")),
+			shiftapi.WSOn("echo", func(sender *shiftapi.WSSender, _ struct{}, msg wsClientMsg) error {
+				return sender.Send(wsServerMsg(msg))
+			}),
+		),
+		shiftapi.WithRouteInfo(shiftapi.RouteInfo{Deprecation: &shiftapi.Deprecation{Date: usersV1Sunset}}),
+	)
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi.json", nil))
+	var spec struct {
+		Channels map[string]struct {
+			Deprecated bool `json:"deprecated"`
+		} `json:"channels"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if !spec.Channels["/ws"].Deprecated {
+		t.Error("expected channel to be deprecated")
+	}
+}
@@ -0,0 +1,49 @@
+//go:build shiftapidev
+
+package shiftapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+func TestDescriptions_docComments(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithDocComments())
+	shiftapi.Handle(api, "GET /accounts", func(r *http.Request, _ DocAccountQuery) (*DocAccount, error) {
+		return &DocAccount{}, nil
+	})
+	shiftapi.Handle(api, "GET /accounts/{id}/owner", func(r *http.Request, _ DocAccountPath) (*DocAccount, error) {
+		return &DocAccount{}, nil
+	})
+	if err := api.ApplyDocComments(); err != nil {
+		t.Fatalf("apply doc comments: %v", err)
+	}
+	spec := api.Spec()
+
+	account := spec.Components.Schemas["DocAccount"].Value
+	want := map[string]string{
+		"id":    "ID uniquely identifies the account.",
+		"owner": "Name of the account holder",
+		"email": "Email receives account notices.",
+		"plan":  "Billing plan",
+	}
+	if got := account.Description; got != "DocAccount is an account as returned by the API." {
+		t.Errorf("unexpected schema description %q", got)
+	}
+	for name, desc := range want {
+		if got := account.Properties[name].Value.Description; got != desc {
+			t.Errorf("%s: expected %q, got %q", name, desc, got)
+		}
+	}
+
+	params := spec.Paths.Find("/accounts").Get.Parameters
+	if got := params.GetByInAndName("query", "expand").Description; got != "Expand lists related resources to include." {
+		t.Errorf("unexpected query description %q", got)
+	}
+	path := spec.Paths.Find("/accounts/{id}/owner").Get.Parameters.GetByInAndName("path", "id")
+	if got := path.Description; got != "ID is the account to fetch." {
+		t.Errorf("unexpected path description %q", got)
+	}
+}
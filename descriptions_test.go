@@ -0,0 +1,116 @@
+package shiftapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+// DocAccount is an account as returned by the API.
+type DocAccount struct {
+	// ID uniquely identifies the account.
+	ID int `json:"id"`
+	// Owner is ignored in favour of the tag.
+	Owner string `json:"owner" doc:"Name of the account holder"`
+	Email string `json:"email"` // Email receives account notices.
+	Plan  string `json:"plan" description:"Billing plan"`
+}
+
+type DocAccountPath struct {
+	// ID is the account to fetch.
+	ID int `path:"id"`
+}
+
+type DocAccountQuery struct {
+	// Expand lists related resources to include.
+	Expand []string `query:"expand"`
+	Fields string   `query:"fields" doc:"Comma-separated fields to return"`
+	Trace  string   `header:"X-Trace" description:"Trace identifier"`
+}
+
+type DocAccountInput struct {
+	ID     int    `path:"id" doc:"Account ID"`
+	Expand string `query:"expand"`
+}
+
+func TestDescriptions_structTags(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /accounts/{id}", func(r *http.Request, _ DocAccountInput) (*DocAccount, error) {
+		return &DocAccount{}, nil
+	})
+	shiftapi.Handle(api, "GET /accounts", func(r *http.Request, _ DocAccountQuery) (*DocAccount, error) {
+		return &DocAccount{}, nil
+	})
+	spec := api.Spec()
+
+	account := spec.Components.Schemas["DocAccount"].Value
+	if got := account.Properties["owner"].Value.Description; got != "Name of the account holder" {
+		t.Errorf("unexpected owner description %q", got)
+	}
+	if got := account.Properties["plan"].Value.Description; got != "Billing plan" {
+		t.Errorf("unexpected plan description %q", got)
+	}
+	if got := account.Properties["id"].Value.Description; got != "" {
+		t.Errorf("expected no doc comment without WithDocComments, got %q", got)
+	}
+
+	params := spec.Paths.Find("/accounts").Get.Parameters
+	if got := params.GetByInAndName("query", "fields").Description; got != "Comma-separated fields to return" {
+		t.Errorf("unexpected query description %q", got)
+	}
+	if got := params.GetByInAndName("header", "X-Trace").Description; got != "Trace identifier" {
+		t.Errorf("unexpected header description %q", got)
+	}
+	path := spec.Paths.Find("/accounts/{id}").Get.Parameters.GetByInAndName("path", "id")
+	if got := path.Description; got != "Account ID" {
+		t.Errorf("unexpected path description %q", got)
+	}
+}
+
+func TestDescriptions_asyncAPI(t *testing.T) {
+	type wsPath struct {
+		Room string `path:"room" doc:"Chat room name"`
+	}
+	type chatMsg struct {
+		Text string `json:"text" doc:"Message body"`
+	}
+	api := shiftapi.New()
+	shiftapi.HandleWS(api, "GET /rooms/{room}",
+		shiftapi.Websocket(
+			func(r *http.Request, _ *shiftapi.WSSender, _ wsPath) (struct{}, error) { return struct{}{}, nil },
+			shiftapi.WSSends(shiftapi.WSMessageType[chatMsg]("chat")),
+			shiftapi.WSOn("chat", func(sender *shiftapi.WSSender, _ struct{}, msg chatMsg) error {
+				return sender.Send(msg)
+			}),
+		),
+	)
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi.json", nil))
+	var spec struct {
+		Channels map[string]struct {
+			Parameters map[string]struct {
+				Description string `json:"description"`
+			} `json:"parameters"`
+		} `json:"channels"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Description string `json:"description"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if got := spec.Channels["/rooms/{room}"].Parameters["room"].Description; got != "Chat room name" {
+		t.Errorf("unexpected channel parameter description %q", got)
+	}
+	if got := spec.Components.Schemas["chatMsg"].Properties["text"].Description; got != "Message body" {
+		t.Errorf("unexpected message property description %q", got)
+	}
+}
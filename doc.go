@@ -30,13 +30,35 @@
 //
 //   - path:"name" — parsed from URL path parameters (e.g. /users/{id})
 //   - json:"name" — parsed from the JSON request body (default for POST/PUT/PATCH)
-//   - query:"name" — parsed from URL query parameters
+//   - query:"name" — parsed from URL query parameters; nested structs and
+//     map[string]T fields use the deepObject style (?name[key]=value), and
+//     slices accept a style option (query:"tag,style=comma", spaceDelimited,
+//     pipeDelimited, or style=form,explode=false)
 //   - header:"name" — parsed from HTTP request headers (input) or set as HTTP
 //     response headers (output)
-//   - form:"name" — parsed from multipart/form-data (for file uploads)
+//   - cookie:"name" — parsed from HTTP request cookies (input) or sent as
+//     Set-Cookie response headers (output)
+//   - form:"name" — parsed from multipart/form-data (for file uploads) or
+//     application/x-www-form-urlencoded (forms without file fields); struct
+//     fields and form:"name,json" fields are decoded from a JSON-encoded part
 //   - validate:"rules" — validated using [github.com/go-playground/validator/v10]
 //     rules and reflected into the OpenAPI schema
-//   - accept:"mime/type" — constrains accepted MIME types on form file fields
+//   - body:"media/type" — binds the raw request body to an [io.Reader],
+//     []byte, or [encoding/json.RawMessage] field instead of decoding it
+//   - accept:"mime/type" — constrains accepted MIME types on form file fields,
+//     checked against the file's sniffed content
+//   - maxsize:"size" — limits the size of each file in a form file field
+//     (bytes, or with a KB, MB, or GB suffix); larger files get 413
+//   - maxfiles:"n" — limits the number of files in a multi-file form field;
+//     more files get 413
+//   - default:"value" — applied when a query, header, cookie, form, or JSON
+//     body field is absent, before validation; documented as the schema default
+//   - deprecated:"true" — marks a body field or a query, header, or cookie
+//     parameter as deprecated in the OpenAPI spec
+//   - example:"value" — documented as the schema example for a scalar or
+//     slice field, parsed into the field's type at registration
+//   - doc:"text" (or description:"text") — documented as the description of
+//     a body field, parameter, or AsyncAPI message field
 //
 // A single input struct can mix path, query, and body fields:
 //
@@ -70,6 +92,18 @@
 // The type parameter must satisfy the [Scalar] constraint (~string, ~int*,
 // ~uint*, ~float*).
 //
+// # Custom scalar types
+//
+// Types implementing [encoding.TextUnmarshaler] (time.Time, netip.Addr, UUID
+// types, custom IDs) are bound from path, query, header, cookie, and form
+// values, and documented as strings with a well-known format where one
+// exists. Use [WithScalarType] to register parse and format functions for
+// other types or to set the documented format:
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithScalarType(shiftapi.ScalarType[netip.Addr]{SchemaFormat: "ipv4"}),
+//	)
+//
 // # File uploads
 //
 // Use [*multipart.FileHeader] fields with the form tag for file uploads:
@@ -79,6 +113,34 @@
 //	    Docs []*multipart.FileHeader `form:"docs"`
 //	}
 //
+// Form types with only text fields accept both multipart/form-data and
+// application/x-www-form-urlencoded bodies, selected by the request's
+// Content-Type.
+//
+// File headers are buffered before the handler runs. To stream large uploads
+// instead, use a [*FilePartReader] field and read its parts in order:
+//
+//	type IngestInput struct {
+//	    Title string                   `form:"title" validate:"required"`
+//	    Video *shiftapi.FilePartReader `form:"video" accept:"video/mp4" maxsize:"4GB"`
+//	}
+//
+// # Raw request bodies
+//
+// Tag a single field with body to receive the request body undecoded, for
+// webhooks that verify signatures or handlers that stream to storage:
+//
+//	type WebhookInput struct {
+//	    Signature string    `header:"X-Signature" validate:"required"`
+//	    Payload   []byte    `body:"application/json"`
+//	}
+//
+// [io.Reader] fields receive the unread request body; []byte and
+// [encoding/json.RawMessage] fields receive it in full, and RawMessage bodies
+// must be valid JSON. Path, query, header, and cookie fields are still bound
+// and validated. The tag value is the documented media type; it defaults to
+// application/json for RawMessage and application/octet-stream otherwise.
+//
 // # Response headers
 //
 // Use the header tag on the Resp struct to set HTTP response headers.
@@ -105,6 +167,25 @@
 //  2. Static headers via [WithResponseHeader] (API → Group → Route)
 //  3. Dynamic headers via header struct tags (innermost, applied last)
 //
+// # Response cookies
+//
+// Use the cookie tag on the Resp struct to set cookies. Cookie-tagged fields
+// are written as Set-Cookie headers, excluded from the JSON response body, and
+// documented as a Set-Cookie response header in the OpenAPI spec. Cookie
+// attributes can be given as tag options, or the field can be an [http.Cookie]
+// for full control (its Name defaults to the tag name):
+//
+//	type LoginResponse struct {
+//	    Session  string       `cookie:"session,path=/,maxage=3600,httponly,secure,samesite=lax"`
+//	    Theme    *string      `cookie:"theme"`    // optional — omitted when nil
+//	    Tracking *http.Cookie `cookie:"tracking"` // sent as-is
+//	    User     string       `json:"user"`
+//	}
+//
+// Supported tag options are path, domain, maxage, secure, httponly,
+// partitioned, and samesite (lax, strict, or none). Invalid options panic at
+// registration time.
+//
 // # No-body responses
 //
 // For status codes that forbid a response body (204 No Content, 304 Not Modified),
@@ -119,6 +200,52 @@
 // Registering a route with status 204 or 304 and a response type that has JSON body
 // fields panics at startup — this catches misconfigurations early.
 //
+// # Multiple success responses
+//
+// A handler whose response type is an interface, such as any, can return one
+// of several types declared with [WithResponse]. Each value is sent with the
+// status declared for its dynamic type, and every declared response is
+// documented under its status code:
+//
+//	shiftapi.Handle(api, "PUT /items/{id}", putItem,
+//	    shiftapi.WithResponse[*Item](http.StatusOK),
+//	    shiftapi.WithResponse[*CreatedItem](http.StatusCreated),
+//	)
+//
+// Such handlers can also return [Redirect] for a 3xx response with a Location
+// header, or [Status] for a bodiless response such as 304 Not Modified.
+// Declare their statuses with [WithRedirect] and [WithEmptyResponse] so they
+// are documented. Any response value can pick its status at runtime by
+// implementing [StatusCoder].
+//
+// # Conditional requests
+//
+// Response types that implement [ETagger] or [LastModifier], or that have
+// header-tagged ETag or Last-Modified fields, send those validators and answer
+// GET requests whose If-None-Match or If-Modified-Since header matches with
+// 304 Not Modified instead of a 2xx response. Unsafe routes opt in to
+// optimistic concurrency with [WithPreconditions], which looks up the
+// resource's current validators and answers with 412 Precondition Failed,
+// without calling the handler, when If-Match, If-None-Match, or
+// If-Unmodified-Since does not hold. Both are documented on the operation.
+//
+// # Codecs
+//
+// Bodies are JSON by default. Register additional formats with [WithCodec];
+// the request decoder is chosen by Content-Type and the response encoder by
+// the Accept header, and every registered media type appears in the spec:
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithCodec("application/xml", shiftapi.XMLCodec{}),
+//	)
+//
+// Once more than one codec is registered, unsupported request types return
+// 415 and unacceptable Accept headers return 406. Error responses stay JSON.
+//
+// [WithStrictDecoding] makes JSON decoding reject unknown keys and trailing
+// data with 400, and [WithUseNumber] decodes numbers in interface fields as
+// [encoding/json.Number]. Both work at the API, group, or route level.
+//
 // # Server-Sent Events
 //
 // Use [HandleSSE] for Server-Sent Events with a typed event writer:
@@ -199,6 +326,31 @@
 // API → parent Group → child Group → Route → handler.
 // Within a single [WithMiddleware] call, the first argument wraps outermost.
 //
+// # Security
+//
+// Create a [SecurityScheme] with [APIKeyScheme], [BearerScheme],
+// [BasicScheme], or [OAuth2Scheme] and require it with [WithSecurity] at any
+// level. The scheme's [Verifier] checks each request's [Credentials] before
+// the handler runs and stores the principal it returns in the request context:
+//
+//	var bearer = shiftapi.BearerScheme("bearerAuth", "JWT", func(r *http.Request, c shiftapi.Credentials) (User, error) {
+//	    return users.ByToken(r.Context(), c.Token)
+//	})
+//	api := shiftapi.New(shiftapi.WithSecurity(bearer))
+//	shiftapi.Handle(api, "GET /me", func(r *http.Request, _ struct{}) (*Profile, error) {
+//	    user, _ := shiftapi.FromContext(r, bearer.Key())
+//	    return profileOf(user), nil
+//	})
+//
+// Requests without valid credentials get 401, or 403 when the verifier
+// returns [ErrForbidden]. Repeated [WithSecurity] options are alternatives,
+// the innermost level that declares security wins, and [WithoutSecurity]
+// makes a route public. Schemes are documented under
+// components.securitySchemes and requirements on each operation; for
+// [HandleWS] routes, the credentials are checked on the handshake and the
+// requirements are documented on the channel's operations in the AsyncAPI
+// spec.
+//
 // # Context values
 //
 // Use [NewContextKey], [SetContext], and [FromContext] to pass typed data from
@@ -251,15 +403,30 @@
 // Use [WithBadRequestError] and [WithInternalServerError] to customize the default
 // 400 and 500 response bodies.
 //
+// [WithMaxBodySize] caps request bodies at any level; bodies over the limit
+// return 413, whose body [WithRequestTooLargeError] customizes.
+//
+// [WithRequestDecompression] decodes gzip and deflate request bodies by their
+// Content-Encoding, rejecting decoded bodies over its limit with 413 and
+// unsupported encodings with 415. Register other encodings with
+// [WithContentDecoder].
+//
+// [WithResponseCompression] gzips responses for clients that accept it, above
+// a size threshold and for an allowlist of content types. [SSEWriter] events
+// are flushed through the encoder, and [HandleWS] upgrades are left alone.
+// Register other encodings, such as zstd, with [WithContentEncoder].
+//
 // # Options
 //
 // [Option] is the primary option type. It works at all three levels: [New],
 // [API.Group]/[Group.Group], and [Handle].
-// [WithError], [WithMiddleware], and [WithResponseHeader] all return [Option].
+// [WithError], [WithMiddleware], [WithResponseHeader], [WithStrictDecoding],
+// [WithUseNumber], [WithMaxBodySize], [WithRequestDecompression], and
+// [WithResponseCompression] all return [Option].
 //
 // Some options are level-specific: [WithInfo] and [WithBadRequestError] only work
-// with [New] ([APIOption]), while [WithStatus] and [WithRouteInfo] only work with
-// [Handle] ([RouteOption]).
+// with [New] ([APIOption]), while [WithStatus], [WithResponse], and
+// [WithRouteInfo] only work with [Handle] ([RouteOption]).
 //
 // Use [ComposeOptions] to bundle multiple [Option] values into a reusable option:
 //
@@ -273,6 +440,38 @@
 // [ComposeAPIOptions], [ComposeGroupOptions], and [ComposeRouteOptions] can mix shared and
 // level-specific options at their respective levels.
 //
+// # Examples
+//
+// Besides example tags, [WithRequestExample] and [WithResponseExample]
+// document whole request and response bodies from Go values. Each example is
+// serialized as JSON into the media type's examples and checked against the
+// generated schema when the route is registered:
+//
+//	shiftapi.Handle(api, "POST /users", createUser,
+//	    shiftapi.WithRequestExample(CreateUser{Name: "Ada"}),
+//	    shiftapi.WithResponseExample(http.StatusCreated, "created", &User{ID: 1, Name: "Ada"}),
+//	)
+//
+// # Descriptions
+//
+// Besides doc tags, [WithDocComments] describes schemas, their fields, and
+// parameters from the Go doc comments in your source. Comments are read with
+// [golang.org/x/tools/go/packages] when a shiftapidev build exports the spec,
+// and only fill descriptions that a tag has not set:
+//
+//	// User is a registered account.
+//	type User struct {
+//	    // Name is shown on the user's profile.
+//	    Name string `json:"name"`
+//	}
+//
+// # Deprecation
+//
+// Set [RouteInfo.Deprecation] to retire a route. The operation (or AsyncAPI
+// channel) is marked deprecated, with its sunset date as x-sunset, and every
+// response carries the Deprecation, Sunset, and Link headers described by
+// [Deprecation].
+//
 // # Built-in endpoints
 //
 // Every API automatically serves:
@@ -280,6 +479,16 @@
 //   - GET /openapi.json — the generated OpenAPI 3.1 spec
 //   - GET /docs — interactive API documentation (Scalar UI)
 //
+// HEAD is answered for every GET route, with the body suppressed; SSE routes
+// send their headers without running the handler. A request to a routed path
+// with an unregistered method gets 405 Method Not Allowed, or 204 No Content
+// for OPTIONS, and an Allow header listing the path's methods. The 405 body is
+// documented as the MethodNotAllowedError schema and customized with
+// [WithMethodNotAllowedError]. Requests with other methods to paths that are
+// not routed at all get 404. API-level middleware runs for all of these, so
+// CORS middleware can answer preflight requests. An explicitly registered
+// OPTIONS route takes precedence.
+//
 // # http.Handler compatibility
 //
 // [API] implements [http.Handler], so it works with any standard middleware,
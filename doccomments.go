@@ -0,0 +1,59 @@
+package shiftapi
+
+import (
+	"cmp"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// fieldDescription returns the description given by a field's doc or
+// description tag.
+func fieldDescription(tag reflect.StructTag) string {
+	return cmp.Or(tag.Get("doc"), tag.Get("description"))
+}
+
+// WithDocComments fills in missing schema and parameter descriptions from the
+// Go doc comments on the types and struct fields they were generated from.
+// Descriptions given by doc or description tags take precedence.
+//
+// Doc comments are not available in a compiled binary, so they are read from
+// source with [golang.org/x/tools/go/packages] when the spec is exported by
+// a shiftapidev build (SHIFTAPI_EXPORT_SPEC), which needs the Go toolchain
+// and the module's source. In other builds the option does nothing: the
+// loader is not compiled in and the spec served at runtime is unaffected.
+//
+//	api := shiftapi.New(shiftapi.WithDocComments())
+func WithDocComments() apiOptionFunc {
+	return func(api *API) {
+		api.docComments = true
+		api.docTypes = make(map[string]reflect.Type)
+	}
+}
+
+// fieldDoc ties a documented parameter to the struct field it was generated
+// from, to be described from the field's doc comment.
+type fieldDoc struct {
+	param *openapi3.Parameter
+	typ   reflect.Type
+	field string
+}
+
+// recordType notes the Go type a component schema may have been generated
+// from, when doc comments are enabled.
+func (a *API) recordType(t reflect.Type) {
+	if a.docComments && t.Kind() == reflect.Struct && t.Name() != "" {
+		a.docTypes[t.Name()] = t
+	}
+}
+
+// recordParamDoc notes the struct field a parameter was generated from, when
+// doc comments are enabled.
+func (a *API) recordParamDoc(p *openapi3.Parameter, t reflect.Type, f reflect.StructField) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if a.docComments {
+		a.paramDocs = append(a.paramDocs, fieldDoc{param: p, typ: t, field: f.Name})
+	}
+}
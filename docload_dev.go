@@ -0,0 +1,207 @@
+//go:build shiftapidev
+
+package shiftapi
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/tools/go/packages"
+)
+
+// typeDoc holds the doc comments of a type declaration and its fields.
+type typeDoc struct {
+	doc    string
+	fields map[string]string
+}
+
+// docIndex maps package paths to the doc comments of their named types.
+type docIndex map[string]map[string]*typeDoc
+
+func (idx docIndex) lookup(t reflect.Type) *typeDoc {
+	return idx[t.PkgPath()][t.Name()]
+}
+
+// applyDocComments loads the source of every package with documented types
+// and copies their doc comments into descriptions that are still empty.
+func (a *API) applyDocComments() error {
+	pkgPaths := make(map[string]bool)
+	for _, t := range a.docTypes {
+		pkgPaths[t.PkgPath()] = true
+	}
+	for _, d := range a.paramDocs {
+		pkgPaths[d.typ.PkgPath()] = true
+	}
+	if len(pkgPaths) == 0 {
+		return nil
+	}
+	idx, err := loadDocIndex(pkgPaths)
+	if err != nil {
+		return fmt.Errorf("shiftapi: loading doc comments: %w", err)
+	}
+
+	for name, ref := range a.spec.Components.Schemas {
+		if t, ok := a.docTypes[name]; ok && ref.Value != nil {
+			describeSchema(idx, t, ref.Value)
+		}
+	}
+	for name, m := range a.asyncSpec.ComponentsEns().Schemas {
+		if t, ok := a.docTypes[name]; ok {
+			describeSchemaMap(idx, t, m)
+		}
+	}
+	for _, d := range a.paramDocs {
+		if td := idx.lookup(d.typ); td != nil && d.param.Description == "" {
+			d.param.Description = td.fields[d.field]
+		}
+	}
+	return nil
+}
+
+// describeSchema sets the empty descriptions of a struct schema and its
+// properties. Properties that reference another component are left alone,
+// since they share that component's schema.
+func describeSchema(idx docIndex, t reflect.Type, s *openapi3.Schema) {
+	td := idx.lookup(t)
+	if td == nil {
+		return
+	}
+	if s.Description == "" {
+		s.Description = td.doc
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			describeFields(idx, f.Type, s)
+			continue
+		}
+		prop := s.Properties[jsonFieldName(f)]
+		if prop == nil || prop.Ref != "" || prop.Value == nil || prop.Value.Description != "" {
+			continue
+		}
+		prop.Value.Description = td.fields[f.Name]
+	}
+}
+
+// describeFields describes the properties promoted from an embedded struct.
+func describeFields(idx docIndex, t reflect.Type, s *openapi3.Schema) {
+	doc := s.Description
+	describeSchema(idx, t, s)
+	s.Description = doc
+}
+
+// describeSchemaMap is describeSchema for the JSON Schema maps of the
+// AsyncAPI spec.
+func describeSchemaMap(idx docIndex, t reflect.Type, m map[string]any) {
+	td := idx.lookup(t)
+	if td == nil {
+		return
+	}
+	if _, ok := m["description"]; !ok && td.doc != "" {
+		m["description"] = td.doc
+	}
+	props, _ := m["properties"].(map[string]any)
+	for f := range t.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		prop, _ := props[jsonFieldName(f)].(map[string]any)
+		if prop == nil || prop["$ref"] != nil || prop["description"] != nil {
+			continue
+		}
+		if doc := td.fields[f.Name]; doc != "" {
+			prop["description"] = doc
+		}
+	}
+}
+
+// loadDocIndex parses the given packages and collects the doc comments of
+// their type declarations. Types of the main package are found through the
+// build info, and those of external test packages by loading tests.
+func loadDocIndex(pkgPaths map[string]bool) (docIndex, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax}
+	var patterns []string
+	var mainPath string
+	for p := range pkgPaths {
+		switch {
+		case p == "main":
+			bi, ok := debug.ReadBuildInfo()
+			if !ok || bi.Path == "" {
+				continue
+			}
+			mainPath = bi.Path
+			patterns = append(patterns, bi.Path)
+		case strings.HasSuffix(p, "_test"):
+			cfg.Tests = true
+			patterns = append(patterns, strings.TrimSuffix(p, "_test"))
+		default:
+			patterns = append(patterns, p)
+		}
+	}
+	if len(patterns) == 0 {
+		return docIndex{}, nil
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := make(docIndex)
+	for _, pkg := range pkgs {
+		path := pkg.PkgPath
+		if path == mainPath && pkg.Name == "main" {
+			path = "main"
+		}
+		types := idx[path]
+		if types == nil {
+			types = make(map[string]*typeDoc)
+			idx[path] = types
+		}
+		for _, file := range pkg.Syntax {
+			collectTypeDocs(file, types)
+		}
+	}
+	return idx, nil
+}
+
+// collectTypeDocs records the doc comments of the type declarations in file.
+func collectTypeDocs(file *ast.File, types map[string]*typeDoc) {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				doc = gd.Doc
+			}
+			td := &typeDoc{doc: commentText(doc), fields: make(map[string]string)}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				for _, f := range st.Fields.List {
+					text := commentText(cmp.Or(f.Doc, f.Comment))
+					for _, name := range f.Names {
+						td.fields[name.Name] = text
+					}
+				}
+			}
+			types[ts.Name.Name] = td
+		}
+	}
+}
+
+func commentText(g *ast.CommentGroup) string {
+	if g == nil {
+		return ""
+	}
+	return strings.TrimSpace(g.Text())
+}
@@ -0,0 +1,151 @@
+package shiftapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// responseExample is a named example response body from WithResponseExample.
+type responseExample struct {
+	status int
+	name   string
+	value  any
+}
+
+// WithRequestExample documents an example request body for the route. The
+// value is a Go value of the route's input type, serialized as JSON into the
+// request body's examples; its path, query, header, and cookie fields are left
+// out. Repeat it to document several examples.
+// Registration panics if the value does not match the request body schema or
+// the route has no JSON request body. Note that nil slices encode as null, so
+// set required slice fields to an empty slice.
+//
+//	shiftapi.Handle(api, "POST /users", createUser,
+//	    shiftapi.WithRequestExample(CreateUser{Name: "Ada", Email: "ada@example.com"}),
+//	)
+func WithRequestExample(value any) routeOptionFunc {
+	return func(cfg *routeConfig) {
+		cfg.requestExamples = append(cfg.requestExamples, value)
+	}
+}
+
+// WithResponseExample documents a named example response body for the given
+// status. The value is serialized as JSON into the response's examples, so
+// error responses declared with [WithError] can have examples too.
+// Registration panics if the value does not match the response schema or no
+// response body is documented for the status.
+//
+//	shiftapi.Handle(api, "GET /users/{id}", getUser,
+//	    shiftapi.WithResponseExample(http.StatusOK, "admin", &User{ID: 1, Name: "Ada", Role: "admin"}),
+//	    shiftapi.WithResponseExample(http.StatusNotFound, "missing", &NotFoundError{Message: "no such user"}),
+//	)
+func WithResponseExample(status int, name string, value any) routeOptionFunc {
+	return func(cfg *routeConfig) {
+		cfg.responseExamples = append(cfg.responseExamples, responseExample{status: status, name: name, value: value})
+	}
+}
+
+// addExamples serializes the route's request and response examples into the
+// operation's media types, checking each against the media type's schema.
+func (a *API) addExamples(op *openapi3.Operation, si schemaInput) error {
+	if len(si.requestExamples) > 0 {
+		if op.RequestBody == nil || si.hasForm || si.rawBody != nil {
+			return fmt.Errorf("request examples require a JSON request body")
+		}
+		for i, value := range si.requestExamples {
+			name := fmt.Sprintf("example%d", i+1)
+			if err := a.addMediaTypeExample(op.RequestBody.Value.Content, name, value, isRequestParamField); err != nil {
+				return fmt.Errorf("request example %d: %w", i+1, err)
+			}
+		}
+	}
+	for _, ex := range si.responseExamples {
+		resp := op.Responses.Value(strconv.Itoa(ex.status))
+		if resp == nil || len(resp.Value.Content) == 0 {
+			return fmt.Errorf("response example %q: no response body is documented for status %d", ex.name, ex.status)
+		}
+		if err := a.addMediaTypeExample(resp.Value.Content, ex.name, ex.value, isResponseHeaderField); err != nil {
+			return fmt.Errorf("response example %q for status %d: %w", ex.name, ex.status, err)
+		}
+	}
+	return nil
+}
+
+// addMediaTypeExample adds the JSON form of value as a named example to each
+// media type of content, after checking it against the media type's schema.
+// Fields of a struct value for which isParam reports true are bound outside
+// the body and left out of the example.
+func (a *API) addMediaTypeExample(content openapi3.Content, name string, value any, isParam func(reflect.StructField) bool) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var example any
+	if err := json.Unmarshal(data, &example); err != nil {
+		return err
+	}
+	if obj, ok := example.(map[string]any); ok {
+		stripExampleFields(reflect.TypeOf(value), obj, isParam)
+	}
+	for _, mt := range content {
+		if schema := a.resolveSchema(mt.Schema); schema != nil {
+			if err := schema.VisitJSON(example); err != nil {
+				return err
+			}
+		}
+		if mt.Examples == nil {
+			mt.Examples = make(openapi3.Examples)
+		}
+		if _, ok := mt.Examples[name]; ok {
+			return fmt.Errorf("duplicate example name %q", name)
+		}
+		mt.Examples[name] = &openapi3.ExampleRef{Value: openapi3.NewExample(example)}
+	}
+	return nil
+}
+
+// stripExampleFields removes the keys of t's parameter fields from obj, the
+// JSON-decoded form of a t value, as the body schemas leave those fields out.
+func stripExampleFields(t reflect.Type, obj map[string]any, isParam func(reflect.StructField) bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for f := range t.Fields() {
+		if f.IsExported() && isParam(f) {
+			delete(obj, jsonFieldName(f))
+		}
+	}
+}
+
+func isRequestParamField(f reflect.StructField) bool {
+	return hasPathTag(f) || hasQueryTag(f) || hasHeaderTag(f) || hasCookieTag(f)
+}
+
+func isResponseHeaderField(f reflect.StructField) bool {
+	return hasHeaderTag(f) || hasCookieTag(f)
+}
+
+// resolveSchema returns the schema a reference points to, looking up
+// component schemas by name.
+func (a *API) resolveSchema(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil {
+		return ref.Value
+	}
+	if name, ok := strings.CutPrefix(ref.Ref, "#/components/schemas/"); ok {
+		if s := a.spec.Components.Schemas[name]; s != nil {
+			return s.Value
+		}
+	}
+	return nil
+}
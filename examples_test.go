@@ -0,0 +1,177 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type ExampleAddress struct {
+	City string `json:"city" example:"Paris"`
+}
+
+type ExampleUser struct {
+	ID      int            `json:"id" example:"42"`
+	Name    string         `json:"name" validate:"required" example:"Ada"`
+	Tags    []string       `json:"tags" example:"admin,ops"`
+	Address ExampleAddress `json:"address"`
+}
+
+type ExampleUserQuery struct {
+	Limit int `query:"limit" example:"20"`
+}
+
+type ExampleNotFound struct {
+	Message string `json:"message"`
+}
+
+func (e *ExampleNotFound) Error() string { return e.Message }
+
+func exampleOf(t *testing.T, content openapi3.Content, name string) any {
+	t.Helper()
+	mt := content.Get("application/json")
+	if mt == nil || mt.Examples[name] == nil {
+		t.Fatalf("expected example %q, got %+v", name, mt)
+	}
+	return mt.Examples[name].Value.Value
+}
+
+func TestExamples_structTag(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users", func(r *http.Request, _ ExampleUserQuery) (*ExampleUser, error) {
+		return nil, nil
+	})
+	spec := api.Spec()
+
+	user := spec.Components.Schemas["ExampleUser"].Value
+	if got := user.Properties["id"].Value.Example; got != 42 {
+		t.Errorf("expected typed int example, got %#v", got)
+	}
+	if got := user.Properties["name"].Value.Example; got != "Ada" {
+		t.Errorf("unexpected name example %#v", got)
+	}
+	if got, ok := user.Properties["tags"].Value.Example.([]string); !ok || len(got) != 2 {
+		t.Errorf("expected slice example, got %#v", user.Properties["tags"].Value.Example)
+	}
+	limit := spec.Paths.Find("/users").Get.Parameters.GetByInAndName("query", "limit")
+	if got := limit.Schema.Value.Example; got != 20 {
+		t.Errorf("expected query param example, got %#v", got)
+	}
+}
+
+func TestExamples_invalidTagPanics(t *testing.T) {
+	type bad struct {
+		Age int `json:"age" example:"old"`
+	}
+	api := newTestAPI(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	shiftapi.Handle(api, "POST /bad", func(r *http.Request, in bad) (*bad, error) {
+		return &in, nil
+	})
+}
+
+func TestExamples_routeOptions(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /users", func(r *http.Request, in ExampleUser) (*ExampleUser, error) {
+		return &in, nil
+	},
+		shiftapi.WithRequestExample(ExampleUser{Name: "Ada", Tags: []string{"admin"}, Address: ExampleAddress{City: "London"}}),
+		shiftapi.WithRequestExample(ExampleUser{Name: "Grace", Tags: []string{}}),
+		shiftapi.WithResponseExample(http.StatusOK, "created", &ExampleUser{ID: 1, Name: "Ada", Tags: []string{}}),
+		shiftapi.WithResponseExample(http.StatusNotFound, "missing", &ExampleNotFound{Message: "no such team"}),
+		shiftapi.WithError[*ExampleNotFound](http.StatusNotFound),
+	)
+	op := api.Spec().Paths.Find("/users").Post
+
+	first := exampleOf(t, op.RequestBody.Value.Content, "example1").(map[string]any)
+	if first["name"] != "Ada" || first["address"].(map[string]any)["city"] != "London" {
+		t.Errorf("unexpected request example %v", first)
+	}
+	exampleOf(t, op.RequestBody.Value.Content, "example2")
+
+	created := exampleOf(t, op.Responses.Value("200").Value.Content, "created").(map[string]any)
+	if created["id"] != float64(1) {
+		t.Errorf("unexpected response example %v", created)
+	}
+	missing := exampleOf(t, op.Responses.Value("404").Value.Content, "missing").(map[string]any)
+	if missing["message"] != "no such team" {
+		t.Errorf("unexpected error example %v", missing)
+	}
+}
+
+type ExampleCreateIn struct {
+	Org  string `path:"org"`
+	Dry  bool   `query:"dry"`
+	Key  string `header:"X-Key"`
+	Name string `json:"name"`
+}
+
+type ExampleCreated struct {
+	Location string `header:"Location"`
+	Name     string `json:"name"`
+}
+
+func TestExamples_mixedInputKeepsOnlyBodyFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /orgs/{org}/users", func(r *http.Request, in ExampleCreateIn) (*ExampleCreated, error) {
+		return &ExampleCreated{Name: in.Name}, nil
+	},
+		shiftapi.WithRequestExample(ExampleCreateIn{Org: "acme", Dry: true, Key: "k", Name: "ada"}),
+		shiftapi.WithResponseExample(http.StatusOK, "created", &ExampleCreated{Location: "/users/1", Name: "ada"}),
+	)
+	op := api.Spec().Paths.Find("/orgs/{org}/users").Post
+
+	req := exampleOf(t, op.RequestBody.Value.Content, "example1").(map[string]any)
+	if len(req) != 1 || req["name"] != "ada" {
+		t.Errorf("expected only body fields in request example, got %v", req)
+	}
+	resp := exampleOf(t, op.Responses.Value("200").Value.Content, "created").(map[string]any)
+	if len(resp) != 1 || resp["name"] != "ada" {
+		t.Errorf("expected only body fields in response example, got %v", resp)
+	}
+}
+
+func TestExamples_invalidRouteExamplesPanic(t *testing.T) {
+	create := func(r *http.Request, in ExampleUser) (*ExampleUser, error) { return &in, nil }
+	tests := map[string][]shiftapi.RouteOption{
+		"request type mismatch": {
+			shiftapi.WithRequestExample(map[string]any{"name": "Ada", "id": "one"}),
+		},
+		"missing required field": {
+			shiftapi.WithRequestExample(map[string]any{"id": 1}),
+		},
+		"response type mismatch": {
+			shiftapi.WithResponseExample(http.StatusOK, "bad", []int{1, 2}),
+		},
+		"undocumented status": {
+			shiftapi.WithResponseExample(http.StatusTeapot, "tea", &ExampleUser{Name: "Ada"}),
+		},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			shiftapi.Handle(newTestAPI(t), "POST /users", create, opts...)
+		})
+	}
+}
+
+func TestExamples_requestExampleWithoutBodyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	shiftapi.Handle(newTestAPI(t), "GET /users", func(r *http.Request, _ ExampleUserQuery) (*ExampleUser, error) {
+		return nil, nil
+	}, shiftapi.WithRequestExample(ExampleUserQuery{Limit: 1}))
+}
@@ -6,3 +6,8 @@ import "github.com/getkin/kin-openapi/openapi3"
 func (a *API) Spec() *openapi3.T {
 	return a.spec
 }
+
+// ApplyDocComments is exported only for testing.
+func (a *API) ApplyDocComments() error {
+	return a.applyDocComments()
+}
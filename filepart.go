@@ -0,0 +1,277 @@
+package shiftapi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+)
+
+var filePartReaderType = reflect.TypeFor[*FilePartReader]()
+
+// FilePartReader streams the file parts of a multipart/form-data request as
+// the handler reads them, without buffering the request to memory or
+// temporary files. Use it as a form-tagged field:
+//
+//	type IngestInput struct {
+//	    Title string                   `form:"title" validate:"required"`
+//	    Video *shiftapi.FilePartReader `form:"video" accept:"video/mp4" maxsize:"4GB"`
+//	}
+//
+// Text form fields are bound from the parts that precede the first file part,
+// so clients must send them first. The maxsize tag limits the size of each
+// file part; reading past it fails with a 413 Request Entity Too Large error,
+// as for buffered uploads. The maxfiles tag limits the number of parts, with
+// the same 413 error from Next. The accept tag is enforced
+// against each part's sniffed content as the part is opened.
+type FilePartReader struct {
+	mr      *multipart.Reader
+	pending *multipart.Part
+	name    string
+	accept  []string
+	limits  fileLimits
+	count   int
+}
+
+// Next returns the next file part of the field, skipping parts sent under
+// other names. It returns [io.EOF] when no parts remain. Any unread data of
+// the previous part is discarded.
+func (fr *FilePartReader) Next() (*FilePart, error) {
+	for {
+		part := fr.pending
+		fr.pending = nil
+		if part == nil {
+			var err error
+			part, err = fr.mr.NextPart()
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			if err != nil {
+				return nil, &filePartError{status: http.StatusBadRequest, field: fr.name, err: err}
+			}
+		}
+		if part.FormName() != fr.name || part.FileName() == "" {
+			continue
+		}
+		fr.count++
+		if fr.limits.maxFiles > 0 && fr.count > fr.limits.maxFiles {
+			return nil, &filePartError{
+				status: http.StatusRequestEntityTooLarge,
+				field:  fr.name,
+				err:    fmt.Errorf("at most %d files allowed", fr.limits.maxFiles),
+			}
+		}
+		ct := part.Header.Get("Content-Type")
+		body := bufio.NewReaderSize(part, sniffLen)
+		if len(fr.accept) > 0 {
+			head, err := body.Peek(sniffLen)
+			if err != nil && err != io.EOF {
+				return nil, &filePartError{status: http.StatusBadRequest, field: fr.name, err: err}
+			}
+			if err := checkFileContentType(ct, head, fr.name, fr.accept); err != nil {
+				return nil, &filePartError{status: http.StatusBadRequest, field: fr.name, err: errors.Unwrap(err)}
+			}
+		}
+		return &FilePart{
+			FileName:    part.FileName(),
+			ContentType: ct,
+			Header:      part.Header,
+			body:        body,
+			name:        fr.name,
+			limit:       fr.limits.maxSize,
+			remaining:   fr.limits.maxSize,
+		}, nil
+	}
+}
+
+// FilePart is a single streamed file part. Read it like any [io.Reader].
+type FilePart struct {
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+
+	body      io.Reader
+	name      string
+	limit     int64 // 0 means unlimited
+	remaining int64
+}
+
+// Read reads from the part body. It returns an error once the part exceeds
+// the field's maxsize limit.
+func (p *FilePart) Read(b []byte) (int, error) {
+	if p.limit <= 0 {
+		return p.body.Read(b)
+	}
+	if p.remaining <= 0 {
+		// Probe for data beyond the limit.
+		var one [1]byte
+		n, err := p.body.Read(one[:])
+		if n > 0 {
+			return 0, &filePartError{
+				status: http.StatusRequestEntityTooLarge,
+				field:  p.name,
+				err:    fmt.Errorf("file part exceeds %d bytes", p.limit),
+			}
+		}
+		return 0, err
+	}
+	if int64(len(b)) > p.remaining {
+		b = b[:p.remaining]
+	}
+	n, err := p.body.Read(b)
+	p.remaining -= int64(n)
+	return n, err
+}
+
+// filePartError is returned while streaming file parts. Handlers usually
+// return it unchanged, and it is written with its own status code.
+type filePartError struct {
+	status int
+	field  string
+	err    error
+}
+
+func (e *filePartError) Error() string {
+	return fmt.Sprintf("invalid form field %q: %v", e.field, e.err)
+}
+
+func (e *filePartError) Unwrap() error { return e.err }
+
+// findFilePartReaderField reports whether a form struct streams its file
+// parts. It panics if the struct has more than one *FilePartReader field or
+// mixes one with *multipart.FileHeader fields (which require buffering).
+// Called at registration time.
+func findFilePartReaderField(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	var found, buffered bool
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasFormTag(f) {
+			continue
+		}
+		if f.Type == fileHeaderType || f.Type == fileHeaderSliceType {
+			buffered = true
+		}
+		if f.Type != filePartReaderType {
+			continue
+		}
+		if found {
+			panic(fmt.Sprintf("shiftapi: struct %s has more than one *FilePartReader field", t.Name()))
+		}
+		found = true
+	}
+	if found && buffered {
+		panic(fmt.Sprintf("shiftapi: struct %s mixes *FilePartReader and *multipart.FileHeader fields — this is not allowed", t.Name()))
+	}
+	return found
+}
+
+// parseFormStreamInto binds a multipart/form-data request to a struct with a
+// *FilePartReader field. Text and JSON fields are read from the parts
+// preceding the first file part; each value is capped at maxMemory bytes. The reader
+// is positioned at that first file part.
+func parseFormStreamInto(rv reflect.Value, r *http.Request, maxMemory int64, scalars scalarRegistry) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	rt := rv.Type()
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return &formParseError{Err: fmt.Errorf("expected multipart/form-data, got %q", mediaType)}
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return &formParseError{Err: fmt.Errorf("failed to read multipart form: %w", err)}
+	}
+
+	textFields := make(map[string]int)
+	jsonFields := make(map[string]bool)
+	var readerField reflect.StructField
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() || !hasFormTag(f) {
+			continue
+		}
+		if f.Type == filePartReaderType {
+			readerField = f
+			continue
+		}
+		textFields[formFieldName(f)] = i
+		if isJSONFormField(f, scalars) {
+			jsonFields[formFieldName(f)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	bound := make(map[string]bool)
+	var pending *multipart.Part
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return &formParseError{Err: fmt.Errorf("failed to read multipart form: %w", err)}
+		}
+		name := part.FormName()
+		if part.FileName() != "" && !jsonFields[name] {
+			pending = part
+			break
+		}
+		i, ok := textFields[name]
+		if !ok || seen[name] {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, maxMemory+1))
+		if err != nil {
+			return &formParseError{Field: name, Err: err}
+		}
+		if int64(len(data)) > maxMemory {
+			return &formParseError{Field: name, Err: errors.New("value too large")}
+		}
+		seen[name] = true
+		if len(data) == 0 {
+			continue
+		}
+		if jsonFields[name] {
+			err = setJSONFormValue(rv.Field(i), data, scalars)
+		} else {
+			err = scalars.setValue(rv.Field(i), string(data))
+		}
+		if err != nil {
+			return &formParseError{Field: name, Err: err}
+		}
+		bound[name] = true
+	}
+
+	for name, i := range textFields {
+		if bound[name] || jsonFields[name] {
+			continue
+		}
+		if def := rt.Field(i).Tag.Get("default"); def != "" {
+			if err := scalars.setValue(rv.Field(i), def); err != nil {
+				return &formParseError{Field: name, Err: err}
+			}
+		}
+	}
+
+	limits, _ := parseFileLimits(readerField)
+	rv.FieldByIndex(readerField.Index).Set(reflect.ValueOf(&FilePartReader{
+		mr:      mr,
+		pending: pending,
+		name:    formFieldName(readerField),
+		accept:  acceptTypes(readerField),
+		limits:  limits,
+	}))
+	return nil
+}
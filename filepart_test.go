@@ -0,0 +1,267 @@
+package shiftapi_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type IngestInput struct {
+	Bucket string                   `query:"bucket"`
+	Title  string                   `form:"title" validate:"required"`
+	Kind   string                   `form:"kind" default:"clip"`
+	Video  *shiftapi.FilePartReader `form:"video" accept:"video/mp4" maxsize:"1KB"`
+}
+
+type IngestResult struct {
+	Bucket string   `json:"bucket"`
+	Title  string   `json:"title"`
+	Kind   string   `json:"kind"`
+	Files  []string `json:"files"`
+	Sizes  []int    `json:"sizes"`
+}
+
+type streamPart struct {
+	name, fileName, contentType string
+	data                        []byte
+}
+
+func doStreamRequest(t *testing.T, api http.Handler, path string, parts []streamPart) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		h := make(textproto.MIMEHeader)
+		if p.fileName != "" {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.fileName+`"`)
+			h.Set("Content-Type", p.contentType)
+		} else {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"`)
+		}
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = pw.Write(p.data)
+	}
+	_ = w.Close()
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+// mp4Data returns n bytes that sniff as video/mp4.
+func mp4Data(n int) []byte {
+	b := make([]byte, n)
+	copy(b, "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom")
+	return b
+}
+
+func ingest(r *http.Request, in IngestInput) (*IngestResult, error) {
+	res := &IngestResult{Bucket: in.Bucket, Title: in.Title, Kind: in.Kind}
+	for {
+		part, err := in.Video.Next()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		n, err := io.Copy(io.Discard, part)
+		if err != nil {
+			return nil, err
+		}
+		res.Files = append(res.Files, part.FileName)
+		res.Sizes = append(res.Sizes, int(n))
+	}
+}
+
+func TestFilePartReader_streamsPartsInOrder(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	resp := doStreamRequest(t, api, "/ingest?bucket=raw", []streamPart{
+		{name: "title", data: []byte("Launch")},
+		{name: "video", fileName: "a.mp4", contentType: "video/mp4", data: mp4Data(100)},
+		{name: "other", fileName: "skip.bin", contentType: "video/mp4", data: mp4Data(32)},
+		{name: "video", fileName: "b.mp4", contentType: "video/mp4", data: mp4Data(1024)},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[IngestResult](t, resp)
+	if got.Bucket != "raw" || got.Title != "Launch" || got.Kind != "clip" {
+		t.Errorf("unexpected fields: %+v", got)
+	}
+	if len(got.Files) != 2 || got.Files[0] != "a.mp4" || got.Files[1] != "b.mp4" {
+		t.Errorf("expected [a.mp4 b.mp4], got %v", got.Files)
+	}
+	if len(got.Sizes) != 2 || got.Sizes[0] != 100 || got.Sizes[1] != 1024 {
+		t.Errorf("expected sizes [100 1024], got %v", got.Sizes)
+	}
+}
+
+func TestFilePartReader_validatesTextFieldsBeforeHandler(t *testing.T) {
+	api := newTestAPI(t)
+	called := false
+	shiftapi.Handle(api, "POST /ingest", func(r *http.Request, in IngestInput) (*IngestResult, error) {
+		called = true
+		return &IngestResult{}, nil
+	})
+
+	// Text fields sent after the first file part are not bound.
+	resp := doStreamRequest(t, api, "/ingest", []streamPart{
+		{name: "video", fileName: "a.mp4", contentType: "video/mp4", data: mp4Data(32)},
+		{name: "title", data: []byte("Late")},
+	})
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	if called {
+		t.Error("handler should not run when validation fails")
+	}
+}
+
+func TestFilePartReader_partTooLarge(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	resp := doStreamRequest(t, api, "/ingest", []streamPart{
+		{name: "title", data: []byte("Big")},
+		{name: "video", fileName: "big.mp4", contentType: "video/mp4", data: mp4Data(1025)},
+	})
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestFilePartReader_tooManyParts(t *testing.T) {
+	type BatchInput struct {
+		Clips *shiftapi.FilePartReader `form:"clips" maxfiles:"1"`
+	}
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /batch", func(r *http.Request, in BatchInput) (*Empty, error) {
+		for {
+			part, err := in.Clips.Next()
+			if err == io.EOF {
+				return &Empty{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if _, err := io.Copy(io.Discard, part); err != nil {
+				return nil, err
+			}
+		}
+	})
+
+	resp := doStreamRequest(t, api, "/batch", []streamPart{
+		{name: "clips", fileName: "a.bin", contentType: "application/octet-stream", data: []byte("a")},
+		{name: "clips", fileName: "b.bin", contentType: "application/octet-stream", data: []byte("b")},
+	})
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestFilePartReader_acceptEnforced(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	resp := doStreamRequest(t, api, "/ingest", []streamPart{
+		{name: "title", data: []byte("Wrong")},
+		{name: "video", fileName: "a.gif", contentType: "image/gif", data: []byte("GIF89a")},
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestFilePartReader_acceptSniffsContent(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	resp := doStreamRequest(t, api, "/ingest", []streamPart{
+		{name: "title", data: []byte("Fake")},
+		{name: "video", fileName: "a.mp4", contentType: "video/mp4", data: []byte("MZ\x90\x00 not a video")},
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestFilePartReader_rejectsNonMultipart(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	resp := doURLEncodedRequest(t, api, http.MethodPost, "/ingest", url.Values{"title": {"x"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestFilePartReader_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /ingest", ingest)
+
+	body := api.Spec().Paths.Find("/ingest").Post.RequestBody.Value
+	if body.Content.Get("application/x-www-form-urlencoded") != nil {
+		t.Error("streaming forms should not document urlencoded bodies")
+	}
+	mt := body.Content.Get("multipart/form-data")
+	if mt == nil {
+		t.Fatal("expected multipart/form-data content")
+	}
+	video := mt.Schema.Value.Properties["video"]
+	if video == nil || !video.Value.Type.Is("string") || video.Value.Format != "binary" {
+		t.Fatalf("expected video to be string/binary, got %+v", video)
+	}
+	if enc := mt.Encoding["video"]; enc == nil || enc.ContentType != "video/mp4" {
+		t.Errorf("expected video encoding video/mp4, got %+v", enc)
+	}
+}
+
+func TestFilePartReader_invalidDeclarationsPanic(t *testing.T) {
+	tests := map[string]func(api *shiftapi.API){
+		"mixed with FileHeader": func(api *shiftapi.API) {
+			type In struct {
+				A *shiftapi.FilePartReader `form:"a"`
+				B *multipart.FileHeader    `form:"b"`
+			}
+			shiftapi.Handle(api, "POST /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+		"two readers": func(api *shiftapi.API) {
+			type In struct {
+				A *shiftapi.FilePartReader `form:"a"`
+				B *shiftapi.FilePartReader `form:"b"`
+			}
+			shiftapi.Handle(api, "POST /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+		"bad maxsize": func(api *shiftapi.API) {
+			type In struct {
+				A *shiftapi.FilePartReader `form:"a" maxsize:"lots"`
+			}
+			shiftapi.Handle(api, "POST /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
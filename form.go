@@ -1,11 +1,15 @@
 package shiftapi
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -28,9 +32,53 @@ func formFieldName(f reflect.StructField) string {
 	return name
 }
 
-// isFileField returns true if the field type is *multipart.FileHeader or []*multipart.FileHeader.
+// isJSONFormField reports whether a form field is decoded from a
+// JSON-encoded part: fields tagged form:"name,json", and struct-typed fields
+// that are not text scalars.
+func isJSONFormField(f reflect.StructField, scalars scalarRegistry) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("form"), ",")
+	for opt := range strings.SplitSeq(opts, ",") {
+		if strings.TrimSpace(opt) == "json" {
+			return true
+		}
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isFileField(f) && !scalars.isTextScalar(t)
+}
+
+// setJSONFormValue decodes a JSON-encoded form part into fv. Body defaults
+// declared on the target struct are applied first, so keys missing from the
+// part keep their defaults.
+func setJSONFormValue(fv reflect.Value, data []byte, scalars scalarRegistry) error {
+	if err := applyBodyDefaults(fv, scalars); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, fv.Addr().Interface())
+}
+
+// jsonFormPart returns the raw JSON of a form part, whether it was sent as a
+// plain value or as a file (browsers send Blob parts with a filename).
+func jsonFormPart(r *http.Request, name string) ([]byte, error) {
+	if r.MultipartForm != nil {
+		if files := r.MultipartForm.File[name]; len(files) > 0 {
+			f, err := files[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(f)
+		}
+	}
+	return []byte(r.FormValue(name)), nil
+}
+
+// isFileField returns true if the field type is *multipart.FileHeader,
+// []*multipart.FileHeader, or *FilePartReader.
 func isFileField(f reflect.StructField) bool {
-	return f.Type == fileHeaderType || f.Type == fileHeaderSliceType
+	return f.Type == fileHeaderType || f.Type == fileHeaderSliceType || f.Type == filePartReaderType
 }
 
 // acceptTypes returns the accepted MIME types from the `accept` struct tag.
@@ -50,28 +98,175 @@ func acceptTypes(f reflect.StructField) []string {
 	return types
 }
 
-// checkFileContentType validates the Content-Type of an uploaded file
-// against the accepted types. Returns an error if the type is not allowed.
-func checkFileContentType(fh *multipart.FileHeader, name string, allowed []string) error {
+// sniffableTypes are the media types [http.DetectContentType] can identify
+// from magic bytes. Files declared as one of these must sniff as an accepted
+// type; other declared types cannot be verified and are trusted as long as
+// the content sniffs as a plausible match: text for textual types such as
+// application/json, and text or generic binary for the rest.
+var sniffableTypes = map[string]bool{
+	"application/ogg": true, "application/pdf": true, "application/postscript": true,
+	"application/vnd.ms-fontobject": true, "application/wasm": true, "application/x-gzip": true,
+	"application/x-rar-compressed": true, "application/zip": true,
+	"audio/aiff": true, "audio/midi": true, "audio/mpeg": true, "audio/wave": true,
+	"font/collection": true, "font/otf": true, "font/ttf": true, "font/woff": true, "font/woff2": true,
+	"image/bmp": true, "image/gif": true, "image/jpeg": true, "image/png": true,
+	"image/vnd.microsoft.icon": true, "image/webp": true, "image/x-icon": true,
+	"text/html": true, "text/xml": true,
+	"video/avi": true, "video/mp4": true, "video/webm": true,
+}
+
+// sniffLen is the number of leading bytes inspected by checkFileContentType.
+const sniffLen = 512
+
+// checkFileContentType validates an uploaded file against the accepted types
+// using both its declared Content-Type and the type sniffed from head, the
+// first bytes of its content. Returns an error if the type is not allowed.
+func checkFileContentType(declared string, head []byte, name string, allowed []string) error {
 	if len(allowed) == 0 {
 		return nil
 	}
-	ct := fh.Header.Get("Content-Type")
-	if slices.Contains(allowed, ct) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if slices.Contains(allowed, sniffed) {
+		return nil
+	}
+	declaredType, _, _ := mime.ParseMediaType(declared)
+	if slices.Contains(allowed, declaredType) && !sniffableTypes[declaredType] && sniffMatches(declaredType, sniffed) {
 		return nil
 	}
 	return &formParseError{
 		Field: name,
-		Err:   fmt.Errorf("content type %q not allowed, accepted: %s", ct, strings.Join(allowed, ", ")),
+		Err:   fmt.Errorf("content type %q (detected %q) not allowed, accepted: %s", declared, sniffed, strings.Join(allowed, ", ")),
 	}
 }
 
-// parseFormInto parses a multipart form request into struct fields tagged with `form`.
-func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64) error {
-	if err := r.ParseMultipartForm(maxMemory); err != nil {
-		return &formParseError{Err: fmt.Errorf("failed to parse multipart form: %w", err)}
+// sniffMatches reports whether content sniffed as sniffed is plausible for a
+// file declared as an unsniffable type. Textual types must sniff as text;
+// XML may also be detected by its declaration.
+func sniffMatches(declaredType, sniffed string) bool {
+	if isTextMediaType(declaredType) {
+		return sniffed == "text/plain" || sniffed == "text/xml" && strings.HasSuffix(declaredType, "xml")
+	}
+	return sniffed == "text/plain" || sniffed == "application/octet-stream"
+}
+
+// checkFileHeader enforces the accept and maxsize constraints of a buffered
+// file upload.
+func checkFileHeader(fh *multipart.FileHeader, name string, allowed []string, limits fileLimits) error {
+	if limits.maxSize > 0 && fh.Size > limits.maxSize {
+		return &filePartError{status: http.StatusRequestEntityTooLarge, field: name, err: fmt.Errorf("file %q exceeds %d bytes", fh.Filename, limits.maxSize)}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return &formParseError{Field: name, Err: err}
+	}
+	defer f.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return &formParseError{Field: name, Err: err}
+	}
+	return checkFileContentType(fh.Header.Get("Content-Type"), head[:n], name, allowed)
+}
+
+// fileLimits holds the size limits declared on a form file field.
+type fileLimits struct {
+	maxSize  int64 // maxsize tag: bytes per file, 0 if unlimited
+	maxFiles int   // maxfiles tag: number of files, 0 if unlimited
+}
+
+// parseFileLimits reads the maxsize and maxfiles tags of a form field.
+// maxsize is a byte count with an optional KB, MB, or GB suffix (powers of
+// 1024). maxfiles is only valid on multi-file fields.
+func parseFileLimits(f reflect.StructField) (fileLimits, error) {
+	var limits fileLimits
+	if tag := strings.TrimSpace(f.Tag.Get("maxsize")); tag != "" {
+		num, mult := tag, int64(1)
+		for _, u := range []struct {
+			suffix string
+			mult   int64
+		}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}} {
+			if s, ok := strings.CutSuffix(strings.ToUpper(tag), u.suffix); ok {
+				num, mult = strings.TrimSpace(s), u.mult
+				break
+			}
+		}
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || n <= 0 {
+			return limits, fmt.Errorf("invalid maxsize %q", tag)
+		}
+		limits.maxSize = n * mult
+	}
+	if tag := f.Tag.Get("maxfiles"); tag != "" {
+		if f.Type != fileHeaderSliceType && f.Type != filePartReaderType {
+			return limits, fmt.Errorf("maxfiles requires []*multipart.FileHeader or *FilePartReader")
+		}
+		n, err := strconv.Atoi(tag)
+		if err != nil || n <= 0 {
+			return limits, fmt.Errorf("invalid maxfiles %q", tag)
+		}
+		limits.maxFiles = n
+	}
+	if limits.maxSize > 0 && !isFileField(f) {
+		return limits, fmt.Errorf("maxsize requires a file field")
+	}
+	return limits, nil
+}
+
+// validateFormFields checks the maxsize and maxfiles tags of a form struct
+// and panics if any are invalid. Called at registration time.
+func validateFormFields(t reflect.Type) {
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasFormTag(f) {
+			continue
+		}
+		if _, err := parseFileLimits(f); err != nil {
+			panic(fmt.Sprintf("shiftapi: field %q: %v", f.Name, err))
+		}
+	}
+}
+
+// hasFileLimits reports whether the form struct type has a file field with a
+// maxsize or maxfiles tag.
+func hasFileLimits(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for f := range t.Fields() {
+		if f.IsExported() && hasFormTag(f) && (f.Tag.Get("maxsize") != "" || f.Tag.Get("maxfiles") != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFileFields reports whether the struct type has any form-tagged file fields.
+// Form types with file fields can only be submitted as multipart/form-data.
+func hasFileFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for f := range t.Fields() {
+		if f.IsExported() && hasFormTag(f) && isFileField(f) {
+			return true
+		}
 	}
+	return false
+}
 
+// parseFormInto parses a form request into struct fields tagged with `form`.
+// Requests with an application/x-www-form-urlencoded Content-Type are parsed
+// with [http.Request.ParseForm] when the form type has no file fields;
+// everything else is parsed as multipart/form-data.
+func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64, scalars scalarRegistry) error {
 	for rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
 			rv.Set(reflect.New(rv.Type().Elem()))
@@ -84,6 +279,15 @@ func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64) error {
 		return fmt.Errorf("form type must be a struct, got %s", rt.Kind())
 	}
 
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" && !hasFileFields(rt) {
+		if err := r.ParseForm(); err != nil {
+			return &formParseError{Err: fmt.Errorf("failed to parse urlencoded form: %w", err)}
+		}
+	} else if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return &formParseError{Err: fmt.Errorf("failed to parse multipart form: %w", err)}
+	}
+
 	for i := range rt.NumField() {
 		field := rt.Field(i)
 		if !field.IsExported() || !hasFormTag(field) {
@@ -102,10 +306,9 @@ func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64) error {
 				}
 				return &formParseError{Field: name, Err: err}
 			}
-			if allowed := acceptTypes(field); allowed != nil {
-				if err := checkFileContentType(fh, name, allowed); err != nil {
-					return err
-				}
+			limits, _ := parseFileLimits(field)
+			if err := checkFileHeader(fh, name, acceptTypes(field), limits); err != nil {
+				return err
 			}
 			fv.Set(reflect.ValueOf(fh))
 			continue
@@ -115,11 +318,14 @@ func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64) error {
 			// Multiple files: []*multipart.FileHeader
 			if r.MultipartForm != nil && r.MultipartForm.File != nil {
 				files := r.MultipartForm.File[name]
-				if allowed := acceptTypes(field); allowed != nil {
-					for _, fh := range files {
-						if err := checkFileContentType(fh, name, allowed); err != nil {
-							return err
-						}
+				limits, _ := parseFileLimits(field)
+				if limits.maxFiles > 0 && len(files) > limits.maxFiles {
+					return &filePartError{status: http.StatusRequestEntityTooLarge, field: name, err: fmt.Errorf("at most %d files allowed, got %d", limits.maxFiles, len(files))}
+				}
+				allowed := acceptTypes(field)
+				for _, fh := range files {
+					if err := checkFileHeader(fh, name, allowed, limits); err != nil {
+						return err
 					}
 				}
 				if len(files) > 0 {
@@ -129,12 +335,29 @@ func parseFormInto(rv reflect.Value, r *http.Request, maxMemory int64) error {
 			continue
 		}
 
-		// Text form field — use r.FormValue and setScalarValue
+		if isJSONFormField(field, scalars) {
+			data, err := jsonFormPart(r, name)
+			if err != nil {
+				return &formParseError{Field: name, Err: err}
+			}
+			if len(data) == 0 {
+				continue
+			}
+			if err := setJSONFormValue(fv, data, scalars); err != nil {
+				return &formParseError{Field: name, Err: err}
+			}
+			continue
+		}
+
+		// Text form field — use r.FormValue and the scalar registry
 		raw := r.FormValue(name)
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
 		if raw == "" {
 			continue
 		}
-		if err := setScalarValue(fv, raw); err != nil {
+		if err := scalars.setValue(fv, raw); err != nil {
 			return &formParseError{Field: name, Err: err}
 		}
 	}
@@ -7,6 +7,7 @@ require (
 	github.com/getkin/kin-openapi v0.128.0
 	github.com/go-playground/validator/v10 v10.30.1
 	github.com/swaggest/go-asyncapi v0.8.1
+	golang.org/x/tools v0.39.0
 )
 
 require (
@@ -22,6 +23,8 @@ require (
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/perimeterx/marshmallow v1.1.5 // indirect
 	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/mod v0.30.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -1,6 +1,7 @@
 package shiftapi
 
 import (
+	"cmp"
 	"net/http"
 	"slices"
 )
@@ -14,6 +15,11 @@ type Group struct {
 	errors            []errorEntry
 	middleware        []func(http.Handler) http.Handler
 	staticRespHeaders []staticResponseHeader
+	decodeMode        decodeMode
+	maxBodySize       int64
+	maxDecompressed   int64
+	compression       *compressionConfig
+	security          *securityConfig
 }
 
 func (g *Group) routerImpl() routerData {
@@ -23,6 +29,11 @@ func (g *Group) routerImpl() routerData {
 		errors:            g.errors,
 		middleware:        g.middleware,
 		staticRespHeaders: g.staticRespHeaders,
+		decodeMode:        g.decodeMode,
+		maxBodySize:       g.maxBodySize,
+		maxDecompressed:   g.maxDecompressed,
+		compression:       g.compression,
+		security:          g.security,
 	}
 }
 
@@ -47,6 +58,11 @@ func (a *API) Group(prefix string, opts ...GroupOption) *Group {
 		errors:            append(slices.Clone(a.globalErrors), cfg.errors...),
 		middleware:        append(slices.Clone(a.middleware), cfg.middleware...),
 		staticRespHeaders: append(slices.Clone(a.staticRespHeaders), cfg.staticRespHeaders...),
+		decodeMode:        a.decodeMode | cfg.decodeMode,
+		maxBodySize:       cmp.Or(cfg.maxBodySize, a.maxBodySize),
+		maxDecompressed:   cmp.Or(cfg.maxDecompressed, a.maxDecompressed),
+		compression:       cmp.Or(cfg.compression, a.compression),
+		security:          cmp.Or(cfg.security, a.security),
 	}
 }
 
@@ -63,6 +79,11 @@ func (g *Group) Group(prefix string, opts ...GroupOption) *Group {
 		errors:            append(slices.Clone(g.errors), cfg.errors...),
 		middleware:        append(slices.Clone(g.middleware), cfg.middleware...),
 		staticRespHeaders: append(slices.Clone(g.staticRespHeaders), cfg.staticRespHeaders...),
+		decodeMode:        g.decodeMode | cfg.decodeMode,
+		maxBodySize:       cmp.Or(cfg.maxBodySize, g.maxBodySize),
+		maxDecompressed:   cmp.Or(cfg.maxDecompressed, g.maxDecompressed),
+		compression:       cmp.Or(cfg.compression, g.compression),
+		security:          cmp.Or(cfg.security, g.security),
 	}
 }
 
@@ -70,6 +91,11 @@ type groupConfig struct {
 	errors            []errorEntry
 	middleware        []func(http.Handler) http.Handler
 	staticRespHeaders []staticResponseHeader
+	decodeMode        decodeMode
+	maxBodySize       int64
+	maxDecompressed   int64
+	compression       *compressionConfig
+	security          *securityConfig
 }
 
 func (c *groupConfig) addError(e errorEntry) {
@@ -83,3 +109,23 @@ func (c *groupConfig) addMiddleware(mw []func(http.Handler) http.Handler) {
 func (c *groupConfig) addStaticResponseHeader(h staticResponseHeader) {
 	c.staticRespHeaders = append(c.staticRespHeaders, h)
 }
+
+func (c *groupConfig) addDecodeMode(m decodeMode) {
+	c.decodeMode |= m
+}
+
+func (c *groupConfig) setMaxBodySize(n int64) {
+	c.maxBodySize = n
+}
+
+func (c *groupConfig) setMaxDecompressedSize(n int64) {
+	c.maxDecompressed = n
+}
+
+func (c *groupConfig) setCompression(cc *compressionConfig) {
+	c.compression = cc
+}
+
+func (c *groupConfig) addSecurity(req securityRequirement) {
+	c.security = c.security.with(req)
+}
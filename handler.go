@@ -17,7 +17,7 @@ import (
 // and other use cases where JSON encoding is inappropriate.
 //
 // The input struct In is parsed and validated identically to [HandlerFunc]:
-// path, query, header, json, and form tags all work as expected. For
+// path, query, header, cookie, json, and form tags all work as expected. For
 // POST/PUT/PATCH methods the body is decoded only when the input struct
 // contains json or form-tagged fields, leaving r.Body available otherwise.
 type RawHandlerFunc[In any] func(w http.ResponseWriter, r *http.Request, in In) error
@@ -54,7 +54,9 @@ func (wt *writeTracker) Unwrap() http.ResponseWriter {
 //   - path:"name" — parsed from URL path parameters (e.g. /users/{id})
 //   - query:"name" — parsed from URL query parameters
 //   - header:"name" — parsed from HTTP request headers
-//   - json:"name" — parsed from the JSON request body (default for POST/PUT/PATCH)
+//   - cookie:"name" — parsed from HTTP request cookies
+//   - json:"name" — parsed from the JSON request body (default for POST/PUT/PATCH),
+//     or with the [Codec] matching the Content-Type when more are registered
 //   - form:"name" — parsed from multipart/form-data (for file uploads)
 //
 // The Resp struct's fields may also use the header tag to set response headers:
@@ -67,8 +69,8 @@ func (wt *writeTracker) Unwrap() http.ResponseWriter {
 // Use struct{} as In for routes that take no input, or as Resp for routes
 // that return no body (e.g. health checks that only need a status code).
 //
-// The [*http.Request] parameter gives access to cookies, path
-// parameters, and other request metadata.
+// The [*http.Request] parameter gives access to path parameters and other
+// request metadata.
 type HandlerFunc[In, Resp any] func(r *http.Request, in In) (Resp, error)
 
 // isNoBodyStatus reports whether the HTTP status code forbids a response body.
@@ -82,23 +84,53 @@ func isNoBodyStatus(status int) bool {
 // parameter lists that were previously passed to parseInput, adapt, and
 // adaptRaw.
 type handlerConfig struct {
-	hasPath          bool
-	hasQuery         bool
-	hasHeader        bool
-	decodeBody       bool
-	hasForm          bool
-	maxUploadSize    int64
-	staticHeaders    []staticResponseHeader
-	errLookup        errorLookup
-	validate         func(any) error
-	badRequestFn     func(error) any
-	internalServerFn func(error) any
+	hasPath           bool
+	hasQuery          bool
+	hasHeader         bool
+	hasCookie         bool
+	decodeBody        bool
+	bodyDefaults      bool       // body fields declare default tags
+	decodeMode        decodeMode // JSON decoding flags
+	paramKeys         []string   // JSON keys of parameter fields, rejected by strict decoding
+	maxBodySize       int64      // request body limit, 0 if none
+	maxDecompressed   int64      // decompressed body limit, 0 if bodies are not decoded
+	contentDecoders   map[string]ContentDecoder
+	hasForm           bool
+	streamParts       bool  // form has a *FilePartReader field
+	rawBodyIndex      []int // index of the body-tagged raw body field, nil if none
+	maxUploadSize     int64
+	codecs            []codecEntry
+	scalars           scalarRegistry
+	staticHeaders     []staticResponseHeader
+	errLookup         errorLookup
+	validate          func(any) error
+	badRequestFn      func(error) any
+	requestTooLargeFn func(error) any
+	internalServerFn  func(error) any
+	conditional       bool // responses carry ETag or Last-Modified validators
 }
 
 // parseInput decodes and validates the typed input from the request. It returns
 // the parsed value and true on success. On failure it writes an error response
 // and returns the zero value and false.
 func parseInput[In any](w http.ResponseWriter, r *http.Request, hc *handlerConfig) (In, bool) {
+	if hc.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, hc.maxBodySize)
+	}
+	if hc.maxDecompressed > 0 {
+		supported, err := decompressBody(w, r, hc.contentDecoders, hc.maxDecompressed)
+		if !supported {
+			writeJSON(w, http.StatusUnsupportedMediaType, &defaultMessage{Message: "unsupported content encoding"})
+			var zero In
+			return zero, false
+		}
+		if err != nil {
+			inputErr := hc.bodyError(err)
+			writeJSON(w, inputErr.status, inputErr.body)
+			var zero In
+			return zero, false
+		}
+	}
 	in, inputErr := parseInputForWS[In](r, hc)
 	if inputErr != nil {
 		writeJSON(w, inputErr.status, inputErr.body)
@@ -107,8 +139,21 @@ func parseInput[In any](w http.ResponseWriter, r *http.Request, hc *handlerConfi
 	return in, true
 }
 
-func adapt[In, Resp any](fn HandlerFunc[In, Resp], hc *handlerConfig, status int, noBody bool, respEnc *respEncoder) http.HandlerFunc {
+func adapt[In, Resp any](fn HandlerFunc[In, Resp], hc *handlerConfig, status int, noBody bool, respEnc *respEncoder, variants responseLookup) http.HandlerFunc {
+	hasBody := !noBody || variants.hasBody()
 	return func(w http.ResponseWriter, r *http.Request) {
+		codec := hc.codecs[0]
+		if hasBody && hc.negotiates() {
+			c, ok := hc.responseCodec(r)
+			if !ok {
+				writeJSON(w, http.StatusNotAcceptable, &defaultMessage{Message: "not acceptable"})
+				return
+			}
+			codec = c
+			w.Header().Add("Vary", "Accept")
+		}
+
+		defer closeRequestBody(r)
 		in, ok := parseInput[In](w, r, hc)
 		if !ok {
 			return
@@ -116,29 +161,60 @@ func adapt[In, Resp any](fn HandlerFunc[In, Resp], hc *handlerConfig, status int
 
 		resp, err := fn(r, in)
 		if err != nil {
-			handleError(w, hc.internalServerFn, err, hc.errLookup)
+			handleError(w, hc, err)
 			return
 		}
 		for _, h := range hc.staticHeaders {
 			w.Header().Set(h.name, h.value)
 		}
+		if er, ok := any(resp).(emptyResponse); ok {
+			er.writeHeader(w)
+			return
+		}
+		status, noBody, respEnc := status, noBody, respEnc
+		if v, ok := variants.match(resp); ok {
+			status, noBody, respEnc = v.status, v.noBody, v.respEnc
+		}
+		if sc, ok := any(resp).(StatusCoder); ok {
+			if code := sc.StatusCode(); code != 0 {
+				status = code
+				noBody = noBody || isNoBodyStatus(code)
+			}
+		}
 		if respEnc != nil {
-			writeResponseHeaders(w, resp)
+			writeResponseHeaders(w, resp, hc.scalars)
+		}
+		if hc.conditional {
+			setValidatorHeaders(w.Header(), resp)
+			if status >= 200 && status < 300 && isSafeMethod(r.Method) && notModified(r, w.Header()) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
 		}
 		if noBody {
 			w.WriteHeader(status)
 			return
 		}
-		if respEnc != nil {
-			writeJSON(w, status, respEnc.encode(resp))
+		if r.Method == http.MethodHead {
+			// GET routes also answer HEAD; send the headers without encoding a body.
+			w.Header().Set("Content-Type", codec.contentType)
+			w.WriteHeader(status)
 			return
 		}
-		writeJSON(w, status, resp)
+		var body any = resp
+		if respEnc != nil {
+			body = respEnc.encode(resp)
+		}
+		if err := writeBody(w, status, codec, body); err != nil {
+			log.Printf("shiftapi: error encoding response: %v", err)
+			handleError(w, hc, err)
+		}
 	}
 }
 
 func adaptRaw[In any](fn RawHandlerFunc[In], hc *handlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		defer closeRequestBody(r)
 		in, ok := parseInput[In](w, r, hc)
 		if !ok {
 			return
@@ -151,7 +227,7 @@ func adaptRaw[In any](fn RawHandlerFunc[In], hc *handlerConfig) http.HandlerFunc
 		wt := &writeTracker{ResponseWriter: w}
 		if err := fn(wt, r, in); err != nil {
 			if !wt.written {
-				handleError(wt, hc.internalServerFn, err, hc.errLookup)
+				handleError(wt, hc, err)
 			} else {
 				log.Printf("shiftapi: raw handler error after response started: %v", err)
 			}
@@ -161,6 +237,7 @@ func adaptRaw[In any](fn RawHandlerFunc[In], hc *handlerConfig) http.HandlerFunc
 
 func adaptSSE[In any](fn SSEHandlerFunc[In], hc *handlerConfig, sendVariants map[reflect.Type]string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		defer closeRequestBody(r)
 		in, ok := parseInput[In](w, r, hc)
 		if !ok {
 			return
@@ -169,6 +246,13 @@ func adaptSSE[In any](fn SSEHandlerFunc[In], hc *handlerConfig, sendVariants map
 		for _, h := range hc.staticHeaders {
 			w.Header().Set(h.name, h.value)
 		}
+		if r.Method == http.MethodHead {
+			// GET routes also answer HEAD; send the stream's headers without
+			// running the handler.
+			(&SSEWriter{w: w}).writeHeaders()
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 
 		wt := &writeTracker{ResponseWriter: w}
 		sse := &SSEWriter{
@@ -178,7 +262,7 @@ func adaptSSE[In any](fn SSEHandlerFunc[In], hc *handlerConfig, sendVariants map
 		}
 		if err := fn(r, in, sse); err != nil {
 			if !wt.written {
-				handleError(wt, hc.internalServerFn, err, hc.errLookup)
+				handleError(wt, hc, err)
 			} else {
 				log.Printf("shiftapi: SSE handler error after response started: %v", err)
 			}
@@ -202,13 +286,25 @@ func parseInputForWS[In any](r *http.Request, hc *handlerConfig) (In, *wsInputEr
 	rv := reflect.ValueOf(&in).Elem()
 
 	if hc.hasForm {
-		if err := parseFormInto(rv, r, hc.maxUploadSize); err != nil {
-			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
+		parse := parseFormInto
+		if hc.streamParts {
+			parse = parseFormStreamInto
+		}
+		if err := parse(rv, r, hc.maxUploadSize, hc.scalars); err != nil {
+			return in, hc.bodyError(err)
 		}
 		rv = reflect.ValueOf(&in).Elem()
 	} else if hc.decodeBody {
-		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
+		codec, ok := hc.requestCodec(r)
+		if !ok {
+			return in, &wsInputError{http.StatusUnsupportedMediaType, &defaultMessage{Message: "unsupported media type"}}
+		}
+		if hc.bodyDefaults {
+			// Defaults are validated at registration time.
+			_ = applyBodyDefaults(rv, hc.scalars)
+		}
+		if err := decodeRequestBody(codec.codec, r.Body, &in, hc.decodeMode, hc.paramKeys); err != nil {
+			return in, hc.bodyError(err)
 		}
 		rv = reflect.ValueOf(&in).Elem()
 		if hc.hasQuery {
@@ -220,26 +316,39 @@ func parseInputForWS[In any](r *http.Request, hc *handlerConfig) (In, *wsInputEr
 		if hc.hasHeader {
 			resetHeaderFields(rv)
 		}
+		if hc.hasCookie {
+			resetCookieFields(rv)
+		}
+	} else if hc.rawBodyIndex != nil {
+		if err := setRawBody(rv, hc.rawBodyIndex, r); err != nil {
+			return in, hc.bodyError(err)
+		}
+		rv = reflect.ValueOf(&in).Elem()
 	}
 
 	if hc.hasQuery {
-		if err := parseQueryInto(rv, r.URL.Query()); err != nil {
+		if err := parseQueryInto(rv, r.URL.Query(), hc.scalars); err != nil {
 			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
 		}
 	}
 	if hc.hasPath {
-		if err := parsePathInto(rv, r); err != nil {
+		if err := parsePathInto(rv, r, hc.scalars); err != nil {
 			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
 		}
 	}
 	if hc.hasHeader {
-		if err := parseHeadersInto(rv, r.Header); err != nil {
+		if err := parseHeadersInto(rv, r.Header, hc.scalars); err != nil {
+			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
+		}
+	}
+	if hc.hasCookie {
+		if err := parseCookiesInto(rv, r, hc.scalars); err != nil {
 			return in, &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
 		}
 	}
 
 	if err := hc.validate(in); err != nil {
-		status, body := resolveError(hc.internalServerFn, err, hc.errLookup)
+		status, body := hc.resolveError(err)
 		return in, &wsInputError{status, body}
 	}
 	return in, nil
@@ -276,6 +385,11 @@ func adaptWSMessages[In any](
 	return func(w http.ResponseWriter, r *http.Request) {
 		in, inputErr := parseInputForWS[In](r, hc)
 
+		// Static headers, including deprecation notices, go on the handshake response.
+		for _, h := range hc.staticHeaders {
+			w.Header().Set(h.name, h.value)
+		}
+
 		conn, err := websocket.Accept(w, r, acceptOpts)
 		if err != nil {
 			// Accept writes its own error response (e.g. 403 for origin
@@ -297,7 +411,7 @@ func adaptWSMessages[In any](
 			// If the error matches a type registered via WithError (or is
 			// a *ValidationError), send it as a structured error frame.
 			// Unregistered errors fall back to a plain StatusInternalError close.
-			status, body := resolveError(hc.internalServerFn, err, hc.errLookup)
+			status, body := hc.resolveError(err)
 			if status != http.StatusInternalServerError {
 				writeWSError(r.Context(), conn, 4000+status%1000, body)
 			} else {
@@ -322,24 +436,57 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 // resolveError matches the error against registered error types and returns
 // the HTTP status code and response body. It checks ValidationError first
 // (always 422), then walks the error chain checking each error's concrete type
-// against the lookup map, and falls back to a 500 response built by
-// internalServerFn.
-func resolveError(internalServerFn func(error) any, err error, lookup errorLookup) (int, any) {
+// against the lookup map. Request bodies that exceeded the route's limit while
+// the handler read them map to 413 and [ErrPreconditionFailed] to 412;
+// everything else falls back to a 500
+// response built by internalServerFn.
+func (hc *handlerConfig) resolveError(err error) (int, any) {
 	if valErr, ok := errors.AsType[*ValidationError](err); ok {
 		return http.StatusUnprocessableEntity, valErr
 	}
-	if len(lookup) > 0 {
-		if status, matched, ok := matchError(err, lookup); ok {
+	if partErr, ok := errors.AsType[*filePartError](err); ok {
+		if partErr.status == http.StatusRequestEntityTooLarge {
+			return partErr.status, hc.requestTooLargeFn(partErr)
+		}
+		return partErr.status, &defaultMessage{Message: partErr.Error()}
+	}
+	if len(hc.errLookup) > 0 {
+		if status, matched, ok := matchError(err, hc.errLookup); ok {
 			return status, matched
 		}
 	}
-	return http.StatusInternalServerError, internalServerFn(err)
+	if _, ok := errors.AsType[*http.MaxBytesError](err); ok {
+		return http.StatusRequestEntityTooLarge, hc.requestTooLargeFn(err)
+	}
+	if errors.Is(err, ErrPreconditionFailed) {
+		return http.StatusPreconditionFailed, &defaultMessage{Message: err.Error()}
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		return http.StatusUnauthorized, &defaultMessage{Message: ErrUnauthorized.Error()}
+	}
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden, &defaultMessage{Message: ErrForbidden.Error()}
+	}
+	return http.StatusInternalServerError, hc.internalServerFn(err)
+}
+
+// bodyError builds the error response for a request body that could not be
+// read or decoded: 413 when it exceeded the route's limit or a file field's
+// maxsize or maxfiles, 400 otherwise.
+func (hc *handlerConfig) bodyError(err error) *wsInputError {
+	if _, ok := errors.AsType[*http.MaxBytesError](err); ok {
+		return &wsInputError{http.StatusRequestEntityTooLarge, hc.requestTooLargeFn(err)}
+	}
+	if partErr, ok := errors.AsType[*filePartError](err); ok && partErr.status == http.StatusRequestEntityTooLarge {
+		return &wsInputError{http.StatusRequestEntityTooLarge, hc.requestTooLargeFn(err)}
+	}
+	return &wsInputError{http.StatusBadRequest, hc.badRequestFn(err)}
 }
 
 // handleError matches the returned error against registered error types and
 // writes the appropriate HTTP response.
-func handleError(w http.ResponseWriter, internalServerFn func(error) any, err error, lookup errorLookup) {
-	status, body := resolveError(internalServerFn, err, lookup)
+func handleError(w http.ResponseWriter, hc *handlerConfig, err error) {
+	status, body := hc.resolveError(err)
 	writeJSON(w, status, body)
 }
 
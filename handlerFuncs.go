@@ -1,6 +1,7 @@
 package shiftapi
 
 import (
+	"cmp"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -44,15 +45,25 @@ type routeSetup struct {
 	hasPath          bool
 	hasQuery         bool
 	hasHeader        bool
+	hasCookie        bool
 	hasBody          bool
 	hasForm          bool
+	streamParts      bool                 // form has a *FilePartReader field
+	rawBody          *reflect.StructField // body-tagged raw body field, if any
 	queryType        reflect.Type
 	headerType       reflect.Type
+	cookieType       reflect.Type
 	pathType         reflect.Type
 	bodyType         reflect.Type
 	allErrors        []errorEntry
 	allStaticHeaders []staticResponseHeader
+	deprecation      []staticResponseHeader // Deprecation, Sunset, and Link headers
 	errLookup        errorLookup
+	decodeMode       decodeMode
+	maxBodySize      int64
+	maxDecompressed  int64
+	compression      *compressionConfig
+	security         *securityConfig
 	muxPattern       string
 }
 
@@ -67,6 +78,9 @@ func prepareRoute[In any](router Router, method, path string, forceMethodBody bo
 	fullPath := strings.TrimRight(rd.prefix, "/") + path
 
 	cfg := applyRouteOptions(options)
+	if cfg.preconditions != nil && isSafeMethod(method) {
+		panic(fmt.Sprintf("shiftapi: WithPreconditions requires an unsafe method, got %s %s", method, path))
+	}
 
 	var in In
 	inType := reflect.TypeOf(in)
@@ -75,7 +89,7 @@ func prepareRoute[In any](router Router, method, path string, forceMethodBody bo
 		rawInType = rawInType.Elem()
 	}
 
-	hasPath, hasQuery, hasHeader, hasBody, hasForm := partitionFields(rawInType)
+	hasPath, hasQuery, hasHeader, hasCookie, hasBody, hasForm := partitionFields(rawInType)
 
 	if hasPath {
 		matches := pathParamRe.FindAllStringSubmatch(fullPath, -1)
@@ -94,11 +108,25 @@ func prepareRoute[In any](router Router, method, path string, forceMethodBody bo
 	if hasHeader {
 		headerType = rawInType
 	}
+	var cookieType reflect.Type
+	if hasCookie {
+		cookieType = rawInType
+	}
 
 	methodRequiresBody := forceMethodBody && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch)
 
+	var rawBody *reflect.StructField
+	if f, ok := findRawBodyField(rawInType, hasBody, hasForm); ok {
+		rawBody = &f
+	}
+
+	if hasForm {
+		validateFormFields(rawInType)
+	}
+	streamParts := hasForm && findFilePartReaderField(rawInType)
+
 	var bodyType reflect.Type
-	if !hasForm {
+	if !hasForm && rawBody == nil {
 		if hasBody {
 			bodyType = inType
 		} else if methodRequiresBody {
@@ -108,6 +136,12 @@ func prepareRoute[In any](router Router, method, path string, forceMethodBody bo
 
 	allErrors := append(rd.errors, cfg.errors...)
 	allStaticHeaders := append(rd.staticRespHeaders, cfg.staticRespHeaders...)
+	var deprecation []staticResponseHeader
+	if cfg.info != nil && cfg.info.Deprecation != nil {
+		// Deprecation headers come first so that declared headers can override them.
+		deprecation = cfg.info.Deprecation.headers()
+		allStaticHeaders = append(deprecation, allStaticHeaders...)
+	}
 
 	var pathType reflect.Type
 	if hasPath {
@@ -129,15 +163,25 @@ func prepareRoute[In any](router Router, method, path string, forceMethodBody bo
 		hasPath:          hasPath,
 		hasQuery:         hasQuery,
 		hasHeader:        hasHeader,
+		hasCookie:        hasCookie,
 		hasBody:          hasBody,
 		hasForm:          hasForm,
+		streamParts:      streamParts,
+		rawBody:          rawBody,
 		queryType:        queryType,
 		headerType:       headerType,
+		cookieType:       cookieType,
 		pathType:         pathType,
 		bodyType:         bodyType,
 		allErrors:        allErrors,
 		allStaticHeaders: allStaticHeaders,
+		deprecation:      deprecation,
 		errLookup:        errLookup,
+		decodeMode:       rd.decodeMode | cfg.decodeMode,
+		maxBodySize:      cmp.Or(cfg.maxBodySize, rd.maxBodySize),
+		maxDecompressed:  cmp.Or(cfg.maxDecompressed, rd.maxDecompressed),
+		compression:      cmp.Or(cfg.compression, rd.compression),
+		security:         cmp.Or(cfg.security, rd.security),
 		muxPattern:       muxPattern,
 	}
 }
@@ -150,12 +194,17 @@ func (s *routeSetup) schemaInput(method string, outType reflect.Type, hasRespHea
 		pathType:           s.pathType,
 		queryType:          s.queryType,
 		headerType:         s.headerType,
+		cookieType:         s.cookieType,
 		bodyType:           s.bodyType,
 		outType:            outType,
 		hasRespHeader:      hasRespHeader,
 		noBody:             noBody,
 		hasForm:            s.hasForm,
 		formType:           s.rawInType,
+		rawBody:            s.rawBody,
+		strictBody:         s.decodeMode&decodeStrict != 0,
+		maxBodySize:        s.maxBodySize,
+		decompress:         s.maxDecompressed > 0,
 		info:               s.cfg.info,
 		status:             s.cfg.status,
 		errors:             s.allErrors,
@@ -163,19 +212,41 @@ func (s *routeSetup) schemaInput(method string, outType reflect.Type, hasRespHea
 		contentType:        s.cfg.contentType,
 		responseSchemaType: s.cfg.responseSchemaType,
 		eventVariants:      s.cfg.eventVariants,
+		responses:          s.cfg.responses,
+		emptyResponses:     s.cfg.emptyResponses,
+		security:           s.security,
+		requestExamples:    s.cfg.requestExamples,
+		responseExamples:   s.cfg.responseExamples,
+		preconditions:      s.cfg.preconditions != nil,
 	}
 }
 
-// wrapAndRegister applies middleware and registers the handler on the mux.
-func (s *routeSetup) wrapAndRegister(router Router, h http.Handler) {
+// wrapAndRegister applies preconditions, security, middleware, deprecation
+// headers, and response compression and registers the handler on the mux.
+// Security checks run inside the middleware, just before the precondition
+// check and the handler. Deprecation headers and compression wrap the
+// middleware so that responses written by middleware carry them too.
+func (s *routeSetup) wrapAndRegister(router Router, h http.Handler, hc *handlerConfig) {
 	rd := router.routerImpl()
+	if s.cfg.preconditions != nil {
+		h = requirePreconditions(h, s.cfg.preconditions, hc)
+	}
+	if s.security != nil {
+		h = requireSecurity(h, s.security, hc)
+	}
 	for i := len(s.cfg.middleware) - 1; i >= 0; i-- {
 		h = s.cfg.middleware[i](h)
 	}
 	for i := len(rd.middleware) - 1; i >= 0; i-- {
 		h = rd.middleware[i](h)
 	}
-	s.api.mux.Handle(s.muxPattern, h)
+	if len(s.deprecation) > 0 {
+		h = announceDeprecation(h, s.deprecation)
+	}
+	if s.compression != nil {
+		h = compressHandler(h, s.compression, s.api.contentEncoders)
+	}
+	s.api.handle(s.muxPattern, h)
 }
 
 // handlerCfg builds the per-request handler configuration from the route setup
@@ -187,21 +258,41 @@ func (s *routeSetup) handlerCfg(method string, forceMethodBody bool) *handlerCon
 	if !decodeBody && !s.hasForm && forceMethodBody {
 		decodeBody = method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
 	}
-	if s.hasForm {
+	if s.hasForm || s.rawBody != nil {
 		decodeBody = false
 	}
+	var rawBodyIndex []int
+	if s.rawBody != nil {
+		rawBodyIndex = s.rawBody.Index
+	}
+	var paramKeys []string
+	if decodeBody && s.decodeMode&decodeStrict != 0 {
+		paramKeys = paramFieldKeys(s.rawInType)
+	}
 	return &handlerConfig{
-		hasPath:          s.hasPath,
-		hasQuery:         s.hasQuery,
-		hasHeader:        s.hasHeader,
-		decodeBody:       decodeBody,
-		hasForm:          s.hasForm,
-		maxUploadSize:    s.api.maxUploadSize,
-		staticHeaders:    s.allStaticHeaders,
-		errLookup:        s.errLookup,
-		validate:         s.api.validateBody,
-		badRequestFn:     s.api.badRequestFn,
-		internalServerFn: s.api.internalServerFn,
+		hasPath:           s.hasPath,
+		hasQuery:          s.hasQuery,
+		hasHeader:         s.hasHeader,
+		hasCookie:         s.hasCookie,
+		decodeBody:        decodeBody,
+		bodyDefaults:      decodeBody && hasBodyDefaults(s.rawInType, s.api.scalars),
+		decodeMode:        s.decodeMode,
+		paramKeys:         paramKeys,
+		maxBodySize:       s.maxBodySize,
+		maxDecompressed:   s.maxDecompressed,
+		contentDecoders:   s.api.contentDecoders,
+		hasForm:           s.hasForm,
+		streamParts:       s.streamParts,
+		rawBodyIndex:      rawBodyIndex,
+		maxUploadSize:     s.api.maxUploadSize,
+		codecs:            s.api.codecs,
+		scalars:           s.api.scalars,
+		staticHeaders:     s.allStaticHeaders,
+		errLookup:         s.errLookup,
+		validate:          s.api.validateBody,
+		badRequestFn:      s.api.badRequestFn,
+		requestTooLargeFn: s.api.requestTooLargeFn,
+		internalServerFn:  s.api.internalServerFn,
 	}
 }
 
@@ -220,34 +311,35 @@ func registerRoute[In, Resp any](
 
 	var respEnc *respEncoder
 	if hasRespHeader {
+		validateRespCookieFields(outType, s.api.scalars)
 		respEnc = newRespEncoder(outType)
 	}
 
 	noBody := isNoBodyStatus(s.cfg.status)
 
 	// Panic if a no-body status code is used with a response type that has JSON body fields.
-	if noBody && outType != nil {
-		ot := outType
-		for ot.Kind() == reflect.Pointer {
-			ot = ot.Elem()
-		}
-		if ot.Kind() == reflect.Struct {
-			for f := range ot.Fields() {
-				if f.IsExported() && !hasHeaderTag(f) {
-					panic(fmt.Sprintf("shiftapi: status %d must not have a response body; response type %s has JSON body field %q — use struct{} or a header-only struct", s.cfg.status, ot.Name(), f.Name))
-				}
-			}
-		}
+	if noBody {
+		checkNoBodyResponseType(s.cfg.status, outType)
+	}
+	variants := buildResponseLookup(s.cfg.responses, reflect.TypeFor[Resp]())
+	checkEmptyResponses(&s.cfg, reflect.TypeFor[Resp]())
+
+	etag, lastModified := responseValidators(outType)
+	for _, e := range s.cfg.responses {
+		e, l := responseValidators(e.typ)
+		etag, lastModified = etag || e, lastModified || l
 	}
 
 	si := s.schemaInput(method, outType, hasRespHeader, noBody)
+	si.etag, si.lastModified = etag, lastModified
 	if err := s.api.updateSchema(si); err != nil {
 		panic(fmt.Sprintf("shiftapi: schema generation failed for %s %s: %v", method, s.fullPath, err))
 	}
 
 	hc := s.handlerCfg(method, true)
-	h := adapt(fn, hc, s.cfg.status, noBody, respEnc)
-	s.wrapAndRegister(router, h)
+	hc.conditional = etag || lastModified
+	h := adapt(fn, hc, s.cfg.status, noBody, respEnc, variants)
+	s.wrapAndRegister(router, h, hc)
 }
 
 // Handle registers a typed handler for the given pattern. The pattern follows
@@ -286,7 +378,7 @@ func registerRawRoute[In any](
 
 	hc := s.handlerCfg(method, false)
 	h := adaptRaw(fn, hc)
-	s.wrapAndRegister(router, h)
+	s.wrapAndRegister(router, h, hc)
 }
 
 // HandleRaw registers a raw handler for the given pattern. Unlike [Handle],
@@ -348,6 +440,31 @@ func registerSSERoute[In any](
 			cfg.addStaticResponseHeader(h)
 		}))
 	}
+	if sseOpts.decodeMode != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.addDecodeMode(sseOpts.decodeMode)
+		}))
+	}
+	if sseOpts.maxBodySize != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setMaxBodySize(sseOpts.maxBodySize)
+		}))
+	}
+	if sseOpts.maxDecompressed != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setMaxDecompressedSize(sseOpts.maxDecompressed)
+		}))
+	}
+	if sseOpts.compression != nil {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setCompression(sseOpts.compression)
+		}))
+	}
+	if sseOpts.security != nil {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.security = sseOpts.security
+		}))
+	}
 
 	s := prepareRoute[In](router, method, path, false, routeOpts)
 	s.cfg.contentType = "text/event-stream"
@@ -360,7 +477,7 @@ func registerSSERoute[In any](
 
 	hc := s.handlerCfg(method, false)
 	h := adaptSSE(fn, hc, sendVariants)
-	s.wrapAndRegister(router, h)
+	s.wrapAndRegister(router, h, hc)
 }
 
 // HandleSSE registers a Server-Sent Events handler for the given pattern.
@@ -420,6 +537,31 @@ func registerWSRoute[In any](
 			cfg.addStaticResponseHeader(h)
 		}))
 	}
+	if wsOpts.decodeMode != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.addDecodeMode(wsOpts.decodeMode)
+		}))
+	}
+	if wsOpts.maxBodySize != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setMaxBodySize(wsOpts.maxBodySize)
+		}))
+	}
+	if wsOpts.maxDecompressed != 0 {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setMaxDecompressedSize(wsOpts.maxDecompressed)
+		}))
+	}
+	if wsOpts.compression != nil {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.setCompression(wsOpts.compression)
+		}))
+	}
+	if wsOpts.security != nil {
+		routeOpts = append(routeOpts, routeOptionFunc(func(cfg *routeConfig) {
+			cfg.security = wsOpts.security
+		}))
+	}
 
 	s := prepareRoute[In](router, method, path, false, routeOpts)
 
@@ -477,7 +619,7 @@ func registerWSRoute[In any](
 	if err := s.api.addWSChannel(
 		s.fullPath, sendType, recvType,
 		msgs.cfg.sendVariants, recvVariants,
-		wsOpts.info, pathFields, s.allErrors,
+		wsOpts.info, pathFields, s.allErrors, s.security,
 	); err != nil {
 		panic(fmt.Sprintf("shiftapi: AsyncAPI generation failed for %s %s: %v", method, s.fullPath, err))
 	}
@@ -494,7 +636,7 @@ func registerWSRoute[In any](
 
 	hc := s.handlerCfg(method, false)
 	h := adaptWSMessages(dispatch, sendVariantMap, hc, wsOpts.wsAcceptOptions, cb, typedSetup)
-	s.wrapAndRegister(router, h)
+	s.wrapAndRegister(router, h, hc)
 }
 
 // HandleWS registers a WebSocket endpoint for the given pattern. Message
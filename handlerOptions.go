@@ -11,9 +11,19 @@ type routeConfig struct {
 	errors             []errorEntry
 	middleware         []func(http.Handler) http.Handler
 	staticRespHeaders  []staticResponseHeader
-	contentType        string            // custom response media type
-	responseSchemaType reflect.Type      // optional type for schema generation under the content type
-	eventVariants      []SSEEventVariant // SSE event variants, set by registerSSERoute
+	contentType        string               // custom response media type
+	responseSchemaType reflect.Type         // optional type for schema generation under the content type
+	eventVariants      []SSEEventVariant    // SSE event variants, set by registerSSERoute
+	responses          []responseEntry      // additional success responses from WithResponse
+	emptyResponses     []emptyResponseEntry // bodiless responses from WithRedirect and WithEmptyResponse
+	requestExamples    []any                // request body examples from WithRequestExample
+	responseExamples   []responseExample    // response body examples from WithResponseExample
+	decodeMode         decodeMode           // JSON decoding flags
+	maxBodySize        int64                // request body limit, 0 to inherit
+	maxDecompressed    int64                // decompressed body limit, 0 to inherit
+	compression        *compressionConfig   // response compression, nil to inherit
+	security           *securityConfig      // security requirements, nil to inherit
+	preconditions      preconditionLookup   // current validators from WithPreconditions, nil if none
 }
 
 func (c *routeConfig) addError(e errorEntry) {
@@ -28,6 +38,26 @@ func (c *routeConfig) addStaticResponseHeader(h staticResponseHeader) {
 	c.staticRespHeaders = append(c.staticRespHeaders, h)
 }
 
+func (c *routeConfig) addDecodeMode(m decodeMode) {
+	c.decodeMode |= m
+}
+
+func (c *routeConfig) setMaxBodySize(n int64) {
+	c.maxBodySize = n
+}
+
+func (c *routeConfig) setMaxDecompressedSize(n int64) {
+	c.maxDecompressed = n
+}
+
+func (c *routeConfig) setCompression(cc *compressionConfig) {
+	c.compression = cc
+}
+
+func (c *routeConfig) addSecurity(req securityRequirement) {
+	c.security = c.security.with(req)
+}
+
 func applyRouteOptions(opts []RouteOption) routeConfig {
 	cfg := routeConfig{status: http.StatusOK}
 	for _, opt := range opts {
@@ -42,6 +72,13 @@ type RouteInfo struct {
 	Summary     string
 	Description string
 	Tags        []string
+	Deprecation *Deprecation // marks the route deprecated, nil if it is not
+
+	// OperationID replaces the operationId generated from the method and
+	// path, such as getUsersById. A WebSocket channel's operations become
+	// subscribe<OperationID> and publish<OperationID>. Operation IDs must be
+	// unique across the API; registration panics on a duplicate.
+	OperationID string
 }
 
 // routeAndWSAndSSEOption implements RouteOption, WSOption, and SSEOption for
@@ -56,7 +93,8 @@ func (o routeAndWSAndSSEOption) applyToRoute(cfg *routeConfig)  { o.routeFn(cfg)
 func (o routeAndWSAndSSEOption) applyToWS(cfg *wsRouteConfig)   { o.wsFn(cfg) }
 func (o routeAndWSAndSSEOption) applyToSSE(cfg *sseRouteConfig) { o.sseFn(cfg) }
 
-// WithRouteInfo sets the route's OpenAPI metadata (summary, description, tags).
+// WithRouteInfo sets the route's OpenAPI metadata (summary, description, tags,
+// deprecation, and operation ID).
 //
 //	shiftapi.Handle(api, "POST /greet", greet, shiftapi.WithRouteInfo(shiftapi.RouteInfo{
 //	    Summary: "Greet a person",
@@ -43,7 +43,7 @@ func resetHeaderFields(rv reflect.Value) {
 // parseHeadersInto populates header-tagged fields on an existing struct value
 // from HTTP headers. Non-header fields are left untouched.
 // Only scalar types and pointer-to-scalar types are supported (no slices).
-func parseHeadersInto(rv reflect.Value, header http.Header) error {
+func parseHeadersInto(rv reflect.Value, header http.Header, scalars scalarRegistry) error {
 	for rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
 			rv.Set(reflect.New(rv.Type().Elem()))
@@ -66,14 +66,18 @@ func parseHeadersInto(rv reflect.Value, header http.Header) error {
 		fv := rv.Field(i)
 		ft := field.Type
 
+		raw := header.Get(name)
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
+
 		// Handle pointer fields (optional headers)
 		if ft.Kind() == reflect.Pointer {
-			raw := header.Get(name)
 			if raw == "" {
 				continue
 			}
 			ptr := reflect.New(ft.Elem())
-			if err := setScalarValue(ptr.Elem(), raw); err != nil {
+			if err := scalars.setValue(ptr.Elem(), raw); err != nil {
 				return &headerParseError{Field: name, Err: err}
 			}
 			fv.Set(ptr)
@@ -81,11 +85,10 @@ func parseHeadersInto(rv reflect.Value, header http.Header) error {
 		}
 
 		// Handle scalar fields
-		raw := header.Get(name)
 		if raw == "" {
 			continue
 		}
-		if err := setScalarValue(fv, raw); err != nil {
+		if err := scalars.setValue(fv, raw); err != nil {
 			return &headerParseError{Field: name, Err: err}
 		}
 	}
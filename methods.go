@@ -0,0 +1,85 @@
+package shiftapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// handle registers h on the mux and records the pattern's method in the
+// route table used to answer OPTIONS requests and build Allow headers.
+func (a *API) handle(pattern string, h http.Handler) {
+	if method, _, ok := strings.Cut(pattern, " "); ok && !slices.Contains(a.methods, method) {
+		a.methods = append(a.methods, method)
+		slices.Sort(a.methods)
+	}
+	a.mux.Handle(pattern, h)
+}
+
+// MethodNotAllowedError is the error passed to the function given to
+// [WithMethodNotAllowedError] when a request's path is routed but its method
+// is not.
+type MethodNotAllowedError struct {
+	Method string   // the request method
+	Allow  []string // the methods the path accepts, as sent in the Allow header
+}
+
+func (e *MethodNotAllowedError) Error() string {
+	return "method not allowed"
+}
+
+// docsRedirectPattern is the catch-all that sends unknown GET paths to the
+// docs. It is not a route of the API, so it never counts towards Allow.
+const docsRedirectPattern = "GET /"
+
+// allowedMethods returns the methods the mux would accept for the request's
+// path, in the form of an Allow header list. HEAD is allowed wherever GET is,
+// and OPTIONS wherever any method is. It returns nil if no route matches the
+// path.
+func (a *API) allowedMethods(r *http.Request) []string {
+	probe := r.WithContext(r.Context())
+	var allow []string
+	for _, m := range a.methods {
+		probe.Method = m
+		if _, pattern := a.mux.Handler(probe); pattern != "" && pattern != docsRedirectPattern {
+			allow = append(allow, m)
+		}
+	}
+	if len(allow) == 0 {
+		return nil
+	}
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+	}
+	if !slices.Contains(allow, http.MethodOptions) {
+		allow = append(allow, http.MethodOptions)
+	}
+	slices.Sort(allow)
+	return allow
+}
+
+// serveUnmatchedMethod answers a request whose method has no route for its
+// path. If the path is routed for other methods, OPTIONS gets 204 No Content
+// and other methods 405 Method Not Allowed with the body built by
+// [WithMethodNotAllowedError], both listing the allowed methods in the Allow
+// header; otherwise the request gets 404 Not Found. API-level middleware wraps
+// the response so that, for example, CORS middleware can answer preflight
+// requests.
+func (a *API) serveUnmatchedMethod(w http.ResponseWriter, r *http.Request, allow []string) {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allow == nil {
+			writeJSON(w, http.StatusNotFound, &defaultMessage{Message: "not found"})
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusMethodNotAllowed, a.methodNotAllowedFn(&MethodNotAllowedError{Method: r.Method, Allow: allow}))
+	})
+	for _, mw := range slices.Backward(a.middleware) {
+		h = mw(h)
+	}
+	h.ServeHTTP(w, r)
+}
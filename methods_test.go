@@ -0,0 +1,180 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+func TestHead_suppressesBody(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodHead, "/users/1", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("expected JSON content type, got %q", got)
+	}
+	if got := readBody(t, resp); got != "" {
+		t.Errorf("expected empty body, got %q", got)
+	}
+}
+
+func TestOptions_listsAllowedMethods(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+	shiftapi.Handle(api, "DELETE /users/{id}", func(r *http.Request, _ struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, shiftapi.WithStatus(http.StatusNoContent))
+	shiftapi.Handle(api, "POST /users", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodOptions, "/users/1", "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Allow"); got != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("unexpected Allow %q", got)
+	}
+
+	// The docs redirect on "/" is not a route of /users.
+	resp = doRequest(t, api, http.MethodOptions, "/users", "")
+	if got := resp.Header.Get("Allow"); got != "OPTIONS, POST" {
+		t.Errorf("unexpected Allow %q", got)
+	}
+}
+
+func TestUnroutedPath_notFound(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+
+	for _, method := range []string{http.MethodPut, http.MethodOptions} {
+		resp := doRequest(t, api, method, "/nowhere", "")
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", method, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Allow"); got != "" {
+			t.Errorf("%s: expected no Allow header, got %q", method, got)
+		}
+	}
+}
+
+func TestOptions_explicitRouteWins(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+	shiftapi.HandleRaw(api, "OPTIONS /users/{id}", func(w http.ResponseWriter, r *http.Request, _ struct{}) error {
+		w.Header().Set("X-Custom", "yes")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	resp := doRequest(t, api, http.MethodOptions, "/users/1", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Custom") != "yes" {
+		t.Fatalf("expected the registered OPTIONS handler, got %d", resp.StatusCode)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+	shiftapi.Handle(api, "DELETE /users/{id}", func(r *http.Request, _ struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, shiftapi.WithStatus(http.StatusNoContent))
+
+	resp := doRequest(t, api, http.MethodPut, "/users/1", "{}")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Allow"); got != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("unexpected Allow %q", got)
+	}
+	body := decodeJSON[map[string]string](t, resp)
+	if body["message"] != "method not allowed" {
+		t.Errorf("unexpected body %v", body)
+	}
+}
+
+type MethodError struct {
+	Code  string   `json:"code"`
+	Allow []string `json:"allow"`
+}
+
+func TestMethodNotAllowed_customError(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithMethodNotAllowedError(func(err error) *MethodError {
+		mna := err.(*shiftapi.MethodNotAllowedError)
+		return &MethodError{Code: "METHOD_NOT_ALLOWED", Allow: mna.Allow}
+	}))
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+	shiftapi.Handle(api, "DELETE /users/{id}", func(r *http.Request, _ struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, shiftapi.WithStatus(http.StatusNoContent))
+
+	resp := doRequest(t, api, http.MethodPut, "/users/1", "{}")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+	body := decodeJSON[MethodError](t, resp)
+	if body.Code != "METHOD_NOT_ALLOWED" || len(body.Allow) != 4 {
+		t.Errorf("unexpected body %+v", body)
+	}
+	schema := api.Spec().Components.Schemas["MethodNotAllowedError"]
+	if schema == nil || schema.Value.Properties["code"] == nil {
+		t.Errorf("expected custom MethodNotAllowedError schema, got %+v", schema)
+	}
+}
+
+func TestHead_sseSendsHeadersOnly(t *testing.T) {
+	api := shiftapi.New()
+	called := false
+	shiftapi.HandleSSE(api, "GET /events", func(r *http.Request, _ struct{}, sse *shiftapi.SSEWriter) error {
+		called = true
+		return sse.Send(sseMessage{Text: "hello"})
+	}, shiftapi.SSESends(
+		shiftapi.SSEEventType[sseMessage]("message"),
+	))
+
+	resp := doRequest(t, api, http.MethodHead, "/events", "")
+	if resp.StatusCode != http.StatusOK || called {
+		t.Fatalf("expected 200 without running the handler, got %d (called=%v)", resp.StatusCode, called)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected event stream content type, got %q", got)
+	}
+	if got := readBody(t, resp); got != "" {
+		t.Errorf("expected empty body, got %q", got)
+	}
+}
+
+func TestMethodNotAllowed_runsAPIMiddleware(t *testing.T) {
+	cors := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			next.ServeHTTP(w, r)
+		})
+	}
+	api := shiftapi.New(shiftapi.WithMiddleware(cors))
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+		return &LoginPage{Title: r.PathValue("id")}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodOptions, "/users/1", "")
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected middleware to run on OPTIONS, got %q", got)
+	}
+}
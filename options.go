@@ -7,11 +7,18 @@ import (
 
 // sharedConfig is the common interface implemented by [*API], [*groupConfig],
 // and [*routeConfig]. It provides the operations that are meaningful at all
-// three levels: adding errors, middleware, and static response headers.
+// three levels: adding errors, middleware, static response headers, JSON
+// decoding flags, request body size limits, response compression, and
+// security requirements.
 type sharedConfig interface {
 	addError(errorEntry)
 	addMiddleware([]func(http.Handler) http.Handler)
 	addStaticResponseHeader(staticResponseHeader)
+	addDecodeMode(decodeMode)
+	setMaxBodySize(int64)
+	setMaxDecompressedSize(int64)
+	setCompression(*compressionConfig)
+	addSecurity(securityRequirement)
 }
 
 // staticResponseHeader is a fixed name/value pair set on every response.
@@ -172,6 +179,26 @@ func WithResponseHeader(name, value string) Option {
 	}
 }
 
+// WithMaxBodySize limits request bodies to n bytes. Bodies are wrapped in
+// [http.MaxBytesReader] before JSON, form, or raw body decoding, and before
+// [HandleRaw] handlers run. Requests that exceed the limit are answered with
+// 413 Request Entity Too Large, customizable with [WithRequestTooLargeError],
+// and 413 is documented on each affected operation. There is no limit by
+// default.
+//
+// WithMaxBodySize returns an [Option] that works at any level. The innermost
+// level wins, so a route can raise or lower its group's limit:
+//
+//	api := shiftapi.New(shiftapi.WithMaxBodySize(1 << 20)) // 1 MB
+//	shiftapi.Handle(api, "POST /imports", importData,
+//	    shiftapi.WithMaxBodySize(64 << 20), // 64 MB
+//	)
+func WithMaxBodySize(n int64) Option {
+	return func(c sharedConfig) {
+		c.setMaxBodySize(n)
+	}
+}
+
 // ComposeOptions combines multiple [Option] values into a single [Option].
 // Use this to create reusable option bundles that work at any level.
 //
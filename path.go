@@ -43,7 +43,7 @@ func resetPathFields(rv reflect.Value) {
 // parsePathInto populates path-tagged fields on an existing struct value
 // from URL path parameters via r.PathValue. Only scalar types are supported;
 // pointers and slices are rejected at registration time.
-func parsePathInto(rv reflect.Value, r *http.Request) error {
+func parsePathInto(rv reflect.Value, r *http.Request, scalars scalarRegistry) error {
 	for rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
 			rv.Set(reflect.New(rv.Type().Elem()))
@@ -67,7 +67,7 @@ func parsePathInto(rv reflect.Value, r *http.Request) error {
 		if raw == "" {
 			continue
 		}
-		if err := setScalarValue(rv.Field(i), raw); err != nil {
+		if err := scalars.setValue(rv.Field(i), raw); err != nil {
 			return &pathParseError{Field: name, Err: err}
 		}
 	}
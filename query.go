@@ -1,6 +1,8 @@
 package shiftapi
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -14,15 +16,16 @@ func hasQueryTag(f reflect.StructField) bool {
 }
 
 // partitionFields inspects a struct type and reports whether it contains
-// path-tagged, query-tagged, header-tagged, body (json-tagged or untagged
-// non-path/query/header) fields, and/or form-tagged fields. It panics if both
+// path-tagged, query-tagged, header-tagged, cookie-tagged, body (json-tagged
+// or untagged non-path/query/header/cookie) fields, and/or form-tagged fields.
+// Raw body-tagged fields are not counted as body fields. It panics if both
 // body and form fields are present.
-func partitionFields(t reflect.Type) (hasPath, hasQuery, hasHeader, hasBody, hasForm bool) {
+func partitionFields(t reflect.Type) (hasPath, hasQuery, hasHeader, hasCookie, hasBody, hasForm bool) {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 	if t.Kind() != reflect.Struct {
-		return false, false, false, false, false
+		return false, false, false, false, false, false
 	}
 	for f := range t.Fields() {
 		if !f.IsExported() {
@@ -34,10 +37,15 @@ func partitionFields(t reflect.Type) (hasPath, hasQuery, hasHeader, hasBody, has
 			hasQuery = true
 		} else if hasHeaderTag(f) {
 			hasHeader = true
+		} else if hasCookieTag(f) {
+			hasCookie = true
+		} else if hasBodyTag(f) {
+			// Raw body fields are handled by findRawBodyField.
+			continue
 		} else if hasFormTag(f) {
 			hasForm = true
 		} else {
-			// Any exported field without a path, query, header, or form tag is a body field
+			// Any exported field without a path, query, header, cookie, or form tag is a body field
 			jsonTag := f.Tag.Get("json")
 			if jsonTag == "-" {
 				continue
@@ -72,7 +80,7 @@ func resetQueryFields(rv reflect.Value) {
 
 // parseQueryInto populates query-tagged fields on an existing struct value
 // from URL query parameters. Non-query fields are left untouched.
-func parseQueryInto(rv reflect.Value, values url.Values) error {
+func parseQueryInto(rv reflect.Value, values url.Values, scalars scalarRegistry) error {
 	for rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
 			rv.Set(reflect.New(rv.Type().Elem()))
@@ -93,51 +101,244 @@ func parseQueryInto(rv reflect.Value, values url.Values) error {
 
 		name := queryFieldName(field)
 		fv := rv.Field(i)
-		ft := field.Type
 
-		// Handle pointer fields (optional params)
-		if ft.Kind() == reflect.Pointer {
-			rawValues, exists := values[name]
-			if !exists || len(rawValues) == 0 {
-				continue
-			}
-			ptr := reflect.New(ft.Elem())
-			if err := setScalarValue(ptr.Elem(), rawValues[0]); err != nil {
-				return &queryParseError{Field: name, Err: err}
+		// Handle nested struct and map fields (deepObject style)
+		if isDeepObjectType(field.Type, scalars) {
+			if err := parseDeepObjectInto(fv, name, values, scalars); err != nil {
+				return err
 			}
-			fv.Set(ptr)
 			continue
 		}
 
-		// Handle slice fields
-		if ft.Kind() == reflect.Slice {
-			rawValues, exists := values[name]
-			if !exists || len(rawValues) == 0 {
+		rawValues := values[name]
+		if len(rawValues) == 0 || rawValues[0] == "" {
+			if def := defaultValues(field); def != nil {
+				if err := setQueryValues(fv, def, scalars); err != nil {
+					return &queryParseError{Field: name, Err: err}
+				}
 				continue
 			}
-			elemType := ft.Elem()
-			slice := reflect.MakeSlice(ft, len(rawValues), len(rawValues))
-			for j, raw := range rawValues {
-				elem := reflect.New(elemType).Elem()
-				if err := setScalarValue(elem, raw); err != nil {
-					return &queryParseError{Field: name, Err: err}
+		}
+		if scalars.isListType(field.Type) {
+			// Tag options are validated at registration time.
+			style, explode, _ := queryArrayStyle(field)
+			rawValues = splitQueryValues(rawValues, style, explode)
+		}
+		if err := setQueryValues(fv, rawValues, scalars); err != nil {
+			return &queryParseError{Field: name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// queryArrayStyle returns the OpenAPI serialization style and explode flag
+// declared by a query field's tag options, e.g. `query:"tag,style=comma"` or
+// `query:"tag,style=form,explode=false"`. The comma style is shorthand for
+// form with explode=false. Without options the style is form with
+// explode=true (repeated keys: ?tag=a&tag=b).
+func queryArrayStyle(f reflect.StructField) (style string, explode bool, err error) {
+	style, explode = "form", true
+	explodeSet := false
+	_, opts, _ := strings.Cut(f.Tag.Get("query"), ",")
+	if opts == "" {
+		return style, explode, nil
+	}
+	for opt := range strings.SplitSeq(opts, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "style":
+			switch val {
+			case "form":
+				style = "form"
+			case "comma":
+				style = "form"
+				if !explodeSet {
+					explode = false
 				}
-				slice.Index(j).Set(elem)
+			case "spaceDelimited", "pipeDelimited":
+				style = val
+				if !explodeSet {
+					explode = false
+				}
+			default:
+				return "", false, fmt.Errorf("unsupported query style %q", val)
 			}
-			fv.Set(slice)
-			continue
+		case "explode":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return "", false, fmt.Errorf("invalid explode value %q", val)
+			}
+			explode, explodeSet = b, true
+		case "":
+		default:
+			return "", false, fmt.Errorf("unknown query option %q", key)
 		}
+	}
+	return style, explode, nil
+}
 
-		// Handle scalar fields
-		raw := values.Get(name)
-		if raw == "" {
-			continue
+// hasQueryStyleOptions reports whether a query field's tag carries options
+// after the parameter name.
+func hasQueryStyleOptions(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("query"), ",")
+	return opts != ""
+}
+
+// splitQueryValues expands delimited array values for non-exploded styles.
+// Exploded arrays arrive as repeated keys and are returned unchanged.
+func splitQueryValues(rawValues []string, style string, explode bool) []string {
+	if explode || len(rawValues) == 0 {
+		return rawValues
+	}
+	sep := ","
+	switch style {
+	case "spaceDelimited":
+		sep = " "
+	case "pipeDelimited":
+		sep = "|"
+	}
+	var out []string
+	for _, raw := range rawValues {
+		out = append(out, strings.Split(raw, sep)...)
+	}
+	return out
+}
+
+// setQueryValues sets a scalar, pointer-to-scalar, or slice field from the raw
+// values of a single query key. Missing or empty values leave the field
+// untouched.
+func setQueryValues(fv reflect.Value, rawValues []string, scalars scalarRegistry) error {
+	if len(rawValues) == 0 {
+		return nil
+	}
+	ft := fv.Type()
+
+	// Handle pointer fields (optional params)
+	if ft.Kind() == reflect.Pointer {
+		ptr := reflect.New(ft.Elem())
+		if err := scalars.setValue(ptr.Elem(), rawValues[0]); err != nil {
+			return err
 		}
-		if err := setScalarValue(fv, raw); err != nil {
-			return &queryParseError{Field: name, Err: err}
+		fv.Set(ptr)
+		return nil
+	}
+
+	// Handle slice fields
+	if scalars.isListType(ft) {
+		elemType := ft.Elem()
+		slice := reflect.MakeSlice(ft, len(rawValues), len(rawValues))
+		for j, raw := range rawValues {
+			elem := reflect.New(elemType).Elem()
+			if err := scalars.setValue(elem, raw); err != nil {
+				return err
+			}
+			slice.Index(j).Set(elem)
 		}
+		fv.Set(slice)
+		return nil
 	}
 
+	// Handle scalar fields
+	if rawValues[0] == "" {
+		return nil
+	}
+	return scalars.setValue(fv, rawValues[0])
+}
+
+// isDeepObjectType reports whether a query field is bound with the OpenAPI
+// deepObject style (?name[key]=value): nested structs, pointers to nested
+// structs, and maps with string keys. Structs that are text scalars (e.g.
+// time.Time) are bound as single values instead.
+func isDeepObjectType(t reflect.Type, scalars scalarRegistry) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if scalars.isTextScalar(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// deepObjectFieldName returns the key used for a nested struct field inside a
+// deepObject query parameter: its query tag name, falling back to the field
+// name.
+func deepObjectFieldName(f reflect.StructField) string {
+	if hasQueryTag(f) {
+		return queryFieldName(f)
+	}
+	return f.Name
+}
+
+// errMalformedDeepObjectKey is returned for a map key of a deepObject query
+// parameter that is not a single, non-empty name[key] pair.
+var errMalformedDeepObjectKey = errors.New("expected a key of the form name[key]")
+
+// parseDeepObjectInto populates a nested struct or map query field from keys
+// of the form name[key]. Pointer-to-struct fields are only allocated when at
+// least one of their keys is present.
+func parseDeepObjectInto(fv reflect.Value, name string, values url.Values, scalars scalarRegistry) error {
+	ft := fv.Type()
+	if ft.Kind() == reflect.Map {
+		prefix := name + "["
+		for key, rawValues := range values {
+			sub, ok := strings.CutPrefix(key, prefix)
+			if !ok {
+				continue
+			}
+			sub, ok = strings.CutSuffix(sub, "]")
+			if !ok || sub == "" || strings.ContainsAny(sub, "[]") {
+				return &queryParseError{Field: key, Err: errMalformedDeepObjectKey}
+			}
+			elem := reflect.New(ft.Elem()).Elem()
+			if err := setQueryValues(elem, rawValues, scalars); err != nil {
+				return &queryParseError{Field: key, Err: err}
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(ft))
+			}
+			fv.SetMapIndex(reflect.ValueOf(sub).Convert(ft.Key()), elem)
+		}
+		return nil
+	}
+
+	st := ft
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	target := reflect.New(st).Elem()
+	if ft.Kind() != reflect.Pointer {
+		target = fv
+	}
+	found := false
+	for i := range st.NumField() {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := name + "[" + deepObjectFieldName(sf) + "]"
+		rawValues, ok := values[key]
+		if !ok {
+			rawValues = defaultValues(sf)
+			if rawValues == nil {
+				continue
+			}
+		} else {
+			found = true
+		}
+		if err := setQueryValues(target.Field(i), rawValues, scalars); err != nil {
+			return &queryParseError{Field: key, Err: err}
+		}
+	}
+	if found && ft.Kind() == reflect.Pointer {
+		fv.Set(target.Addr())
+	}
 	return nil
 }
 
@@ -152,7 +353,17 @@ func queryFieldName(f reflect.StructField) string {
 }
 
 // setScalarValue parses a string and sets the value on a reflect.Value.
+// Types implementing [encoding.TextUnmarshaler] decode themselves; otherwise
+// the primitive kinds are supported.
 func setScalarValue(v reflect.Value, raw string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(raw)); err != nil {
+				return fmt.Errorf("invalid value %q: %w", raw, err)
+			}
+			return nil
+		}
+	}
 	switch v.Kind() {
 	case reflect.String:
 		v.SetString(raw)
@@ -0,0 +1,309 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type Pagination struct {
+	Page int `query:"page"`
+	Size int `query:"size" validate:"max=100"`
+}
+
+type IssueFilter struct {
+	Status string   `query:"status" validate:"omitempty,oneof=open closed"`
+	Owner  string   `query:"owner"`
+	Labels []string `query:"label"`
+}
+
+type DeepObjectInput struct {
+	Filter *IssueFilter      `query:"filter"`
+	Paging Pagination        `query:"paging"`
+	Meta   map[string]string `query:"meta"`
+	Counts map[string]int    `query:"counts"`
+	Q      string            `query:"q"`
+}
+
+type DeepObjectResult struct {
+	HasFilter bool              `json:"has_filter"`
+	Status    string            `json:"status"`
+	Owner     string            `json:"owner"`
+	Labels    []string          `json:"labels"`
+	Page      int               `json:"page"`
+	Size      int               `json:"size"`
+	Meta      map[string]string `json:"meta"`
+	Counts    map[string]int    `json:"counts"`
+	Q         string            `json:"q"`
+}
+
+func deepObjectHandler(r *http.Request, in DeepObjectInput) (*DeepObjectResult, error) {
+	res := &DeepObjectResult{
+		HasFilter: in.Filter != nil,
+		Page:      in.Paging.Page,
+		Size:      in.Paging.Size,
+		Meta:      in.Meta,
+		Counts:    in.Counts,
+		Q:         in.Q,
+	}
+	if in.Filter != nil {
+		res.Status = in.Filter.Status
+		res.Owner = in.Filter.Owner
+		res.Labels = in.Filter.Labels
+	}
+	return res, nil
+}
+
+func TestQueryDeepObject_parsesNestedStructAndMaps(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	resp := doRequest(t, api, http.MethodGet,
+		"/issues?filter[status]=open&filter[owner]=me&filter[label]=bug&filter[label]=ui"+
+			"&paging[page]=2&paging[size]=50&meta[source]=web&counts[a]=1&counts[b]=2&q=x", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[DeepObjectResult](t, resp)
+	if !got.HasFilter || got.Status != "open" || got.Owner != "me" {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+	if len(got.Labels) != 2 || got.Labels[0] != "bug" || got.Labels[1] != "ui" {
+		t.Errorf("expected labels [bug ui], got %v", got.Labels)
+	}
+	if got.Page != 2 || got.Size != 50 {
+		t.Errorf("expected paging 2/50, got %d/%d", got.Page, got.Size)
+	}
+	if got.Meta["source"] != "web" {
+		t.Errorf("expected meta[source]=web, got %v", got.Meta)
+	}
+	if got.Counts["a"] != 1 || got.Counts["b"] != 2 {
+		t.Errorf("expected counts a=1 b=2, got %v", got.Counts)
+	}
+	if got.Q != "x" {
+		t.Errorf("expected q=x, got %q", got.Q)
+	}
+}
+
+func TestQueryDeepObject_absentPointerStaysNil(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	resp := doRequest(t, api, http.MethodGet, "/issues?q=x", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[DeepObjectResult](t, resp)
+	if got.HasFilter {
+		t.Error("expected nil filter when no filter[...] keys are sent")
+	}
+	if got.Meta != nil {
+		t.Errorf("expected nil meta, got %v", got.Meta)
+	}
+}
+
+func TestQueryDeepObject_invalidValueReturns400(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	for _, q := range []string{"paging[page]=two", "counts[a]=x"} {
+		resp := doRequest(t, api, http.MethodGet, "/issues?"+q, "")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, resp.StatusCode)
+		}
+	}
+}
+
+func TestQueryDeepObject_malformedMapKeyReturns400(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	for _, key := range []string{"meta[a][b", "meta[a]]b", "meta[a][b]", "meta[]", "meta[a"} {
+		resp := doRequest(t, api, http.MethodGet, "/issues?"+url.QueryEscape(key)+"=x", "")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", key, resp.StatusCode, readBody(t, resp))
+		}
+	}
+}
+
+func TestQueryDeepObject_nestedValidation(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	resp := doRequest(t, api, http.MethodGet, "/issues?filter[status]=stale", "")
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestQueryDeepObject_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /issues", deepObjectHandler)
+
+	params := map[string]*openapi3.Parameter{}
+	for _, p := range api.Spec().Paths.Find("/issues").Get.Parameters {
+		params[p.Value.Name] = p.Value
+	}
+
+	filter := params["filter"]
+	if filter == nil {
+		t.Fatal("expected filter parameter")
+	}
+	if filter.Style != openapi3.SerializationDeepObject || filter.Explode == nil || !*filter.Explode {
+		t.Errorf("expected deepObject/explode=true, got style=%q explode=%v", filter.Style, filter.Explode)
+	}
+	fs := filter.Schema.Value
+	if !fs.Type.Is("object") {
+		t.Fatalf("expected object schema, got %v", fs.Type)
+	}
+	if len(fs.Properties["status"].Value.Enum) != 2 {
+		t.Errorf("expected status enum from oneof, got %v", fs.Properties["status"].Value.Enum)
+	}
+	if !fs.Properties["label"].Value.Type.Is("array") {
+		t.Errorf("expected label to be an array, got %v", fs.Properties["label"].Value.Type)
+	}
+
+	paging := params["paging"].Schema.Value
+	if max := paging.Properties["size"].Value.Max; max == nil || *max != 100 {
+		t.Errorf("expected size max=100, got %v", max)
+	}
+
+	counts := params["counts"].Schema.Value
+	if counts.AdditionalProperties.Schema == nil || !counts.AdditionalProperties.Schema.Value.Type.Is("integer") {
+		t.Errorf("expected integer additionalProperties for counts, got %+v", counts.AdditionalProperties)
+	}
+
+	if params["q"].Style != "" {
+		t.Errorf("scalar query params should keep the default style, got %q", params["q"].Style)
+	}
+}
+
+func TestQueryDeepObject_nestedTooDeepPanics(t *testing.T) {
+	type Inner struct {
+		X int `query:"x"`
+	}
+	type Outer struct {
+		Inner Inner `query:"inner"`
+	}
+	type TooDeepInput struct {
+		Outer Outer `query:"outer"`
+	}
+	api := newTestAPI(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for nested deepObject parameter")
+		}
+	}()
+	shiftapi.Handle(api, "GET /deep", func(r *http.Request, in TooDeepInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+}
+
+type ArrayStyleInput struct {
+	Repeated []string `query:"r"`
+	Comma    []int    `query:"c,style=comma"`
+	Form     []string `query:"f,style=form,explode=false"`
+	Space    []string `query:"s,style=spaceDelimited"`
+	Pipe     []string `query:"p,style=pipeDelimited"`
+}
+
+func TestQueryArrayStyles_parse(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /styles", func(r *http.Request, in ArrayStyleInput) (*ArrayStyleInput, error) {
+		return &in, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/styles?r=a&r=b&c=1,2,3&f=x,y&s=one%20two&p=left|right", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[ArrayStyleInput](t, resp)
+	if !slices.Equal(got.Repeated, []string{"a", "b"}) {
+		t.Errorf("repeated: got %v", got.Repeated)
+	}
+	if !slices.Equal(got.Comma, []int{1, 2, 3}) {
+		t.Errorf("comma: got %v", got.Comma)
+	}
+	if !slices.Equal(got.Form, []string{"x", "y"}) {
+		t.Errorf("form explode=false: got %v", got.Form)
+	}
+	if !slices.Equal(got.Space, []string{"one", "two"}) {
+		t.Errorf("spaceDelimited: got %v", got.Space)
+	}
+	if !slices.Equal(got.Pipe, []string{"left", "right"}) {
+		t.Errorf("pipeDelimited: got %v", got.Pipe)
+	}
+}
+
+func TestQueryArrayStyles_invalidElementReturns400(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /styles", func(r *http.Request, in ArrayStyleInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/styles?c=1,two", "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestQueryArrayStyles_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /styles", func(r *http.Request, in ArrayStyleInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	want := map[string]struct {
+		style   string
+		explode *bool
+	}{
+		"r": {"", nil},
+		"c": {"form", new(false)},
+		"f": {"form", new(false)},
+		"s": {"spaceDelimited", new(false)},
+		"p": {"pipeDelimited", new(false)},
+	}
+	for _, p := range api.Spec().Paths.Find("/styles").Get.Parameters {
+		w, ok := want[p.Value.Name]
+		if !ok {
+			continue
+		}
+		if p.Value.Style != w.style {
+			t.Errorf("%s: expected style %q, got %q", p.Value.Name, w.style, p.Value.Style)
+		}
+		if (w.explode == nil) != (p.Value.Explode == nil) || (w.explode != nil && *w.explode != *p.Value.Explode) {
+			t.Errorf("%s: expected explode %v, got %v", p.Value.Name, w.explode, p.Value.Explode)
+		}
+	}
+}
+
+func TestQueryArrayStyles_invalidOptionsPanic(t *testing.T) {
+	tests := map[string]func(api *shiftapi.API){
+		"unknown style": func(api *shiftapi.API) {
+			type In struct {
+				Tags []string `query:"tag,style=matrix"`
+			}
+			shiftapi.Handle(api, "GET /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+		"non-slice field": func(api *shiftapi.API) {
+			type In struct {
+				Tag string `query:"tag,style=comma"`
+			}
+			shiftapi.Handle(api, "GET /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
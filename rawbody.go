@@ -0,0 +1,104 @@
+package shiftapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+var (
+	ioReaderType       = reflect.TypeFor[io.Reader]()
+	ioReadCloserType   = reflect.TypeFor[io.ReadCloser]()
+	byteSliceType      = reflect.TypeFor[[]byte]()
+	jsonRawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// hasBodyTag returns true if the struct field has a `body` tag. The tag value
+// is the documented media type and may be empty.
+func hasBodyTag(f reflect.StructField) bool {
+	_, ok := f.Tag.Lookup("body")
+	return ok
+}
+
+// rawBodyMediaType returns the media type documented for a body-tagged field.
+// It defaults to application/json for json.RawMessage and
+// application/octet-stream otherwise.
+func rawBodyMediaType(f reflect.StructField) string {
+	if mt := f.Tag.Get("body"); mt != "" {
+		return mt
+	}
+	if f.Type == jsonRawMessageType {
+		return "application/json"
+	}
+	return "application/octet-stream"
+}
+
+// findRawBodyField returns the body-tagged field of a struct type, if any. It
+// panics if more than one field is body-tagged, if the field type is not
+// io.Reader, io.ReadCloser, []byte, or json.RawMessage, or if the struct also
+// has json body or form fields. Called at registration time.
+func findRawBodyField(t reflect.Type, hasBody, hasForm bool) (reflect.StructField, bool) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	var found reflect.StructField
+	ok := false
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasBodyTag(f) {
+			continue
+		}
+		if ok {
+			panic(fmt.Sprintf("shiftapi: struct %s has more than one body-tagged field", t.Name()))
+		}
+		switch f.Type {
+		case ioReaderType, ioReadCloserType, byteSliceType, jsonRawMessageType:
+		default:
+			panic(fmt.Sprintf("shiftapi: body-tagged field %q must be io.Reader, io.ReadCloser, []byte, or json.RawMessage, got %s", f.Name, f.Type))
+		}
+		found, ok = f, true
+	}
+	if ok && (hasBody || hasForm) {
+		panic(fmt.Sprintf("shiftapi: struct %s has a body-tagged field and json or form fields — this is not allowed", t.Name()))
+	}
+	return found, ok
+}
+
+// setRawBody binds the request body to the body-tagged field at index.
+// Reader fields receive r.Body unread; []byte and json.RawMessage fields
+// receive the full body, and json.RawMessage must be valid JSON.
+func setRawBody(rv reflect.Value, index []int, r *http.Request) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	fv := rv.FieldByIndex(index)
+	switch fv.Type() {
+	case ioReaderType, ioReadCloserType:
+		fv.Set(reflect.ValueOf(r.Body))
+		return nil
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &rawBodyError{Err: err}
+	}
+	if fv.Type() == jsonRawMessageType && !json.Valid(data) {
+		return &rawBodyError{Err: fmt.Errorf("body is not valid JSON")}
+	}
+	fv.SetBytes(data)
+	return nil
+}
+
+// rawBodyError is returned when a raw request body cannot be read.
+type rawBodyError struct {
+	Err error
+}
+
+func (e *rawBodyError) Error() string {
+	return fmt.Sprintf("invalid request body: %v", e.Err)
+}
+
+func (e *rawBodyError) Unwrap() error { return e.Err }
@@ -0,0 +1,163 @@
+package shiftapi_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type WebhookInput struct {
+	Source    string `path:"source"`
+	Signature string `header:"X-Signature" validate:"required"`
+	Payload   []byte `body:"application/octet-stream"`
+}
+
+type ProxyInput struct {
+	Target string    `query:"target"`
+	Body   io.Reader `body:"text/plain"`
+}
+
+type RawJSONInput struct {
+	ID   int             `path:"id"`
+	Data json.RawMessage `body:""`
+}
+
+type RawBodyResult struct {
+	Source string `json:"source"`
+	Size   int    `json:"size"`
+	Text   string `json:"text"`
+}
+
+func TestRawBody_bytesWithPathAndHeader(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /hooks/{source}", func(r *http.Request, in WebhookInput) (*RawBodyResult, error) {
+		return &RawBodyResult{Source: in.Source, Size: len(in.Payload), Text: string(in.Payload)}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/hooks/github", "raw\x00bytes", map[string]string{"Content-Type": "application/octet-stream", "X-Signature": "sig"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[RawBodyResult](t, resp)
+	if got.Source != "github" || got.Text != "raw\x00bytes" || got.Size != 9 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestRawBody_validationStillRuns(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /hooks/{source}", func(r *http.Request, in WebhookInput) (*RawBodyResult, error) {
+		return &RawBodyResult{}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/hooks/github", "data", map[string]string{"Content-Type": "application/octet-stream"})
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestRawBody_readerIsUnread(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /proxy", func(r *http.Request, in ProxyInput) (*RawBodyResult, error) {
+		data, err := io.ReadAll(in.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &RawBodyResult{Source: in.Target, Text: string(data)}, nil
+	})
+
+	resp := doRequestWithHeaders(t, api, http.MethodPost, "/proxy?target=upstream", `{"not":"decoded"}`, map[string]string{"Content-Type": "text/plain"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[RawBodyResult](t, resp)
+	if got.Source != "upstream" || got.Text != `{"not":"decoded"}` {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestRawBody_rawMessage(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /docs/{id}", func(r *http.Request, in RawJSONInput) (*RawBodyResult, error) {
+		return &RawBodyResult{Size: in.ID, Text: string(in.Data)}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodPost, "/docs/7", `[1, {"a": true}]`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[RawBodyResult](t, resp)
+	if got.Size != 7 || got.Text != `[1, {"a": true}]` {
+		t.Errorf("unexpected result: %+v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodPost, "/docs/7", `{broken`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid JSON, got %d", resp.StatusCode)
+	}
+}
+
+func TestRawBody_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /hooks/{source}", func(r *http.Request, in WebhookInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+	shiftapi.Handle(api, "POST /docs/{id}", func(r *http.Request, in RawJSONInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	hook := api.Spec().Paths.Find("/hooks/{source}").Post.RequestBody.Value
+	if len(hook.Content) != 1 {
+		t.Fatalf("expected one media type, got %v", hook.Content)
+	}
+	mt := hook.Content.Get("application/octet-stream")
+	if mt == nil {
+		t.Fatal("expected application/octet-stream content")
+	}
+	if !mt.Schema.Value.Type.Is("string") || mt.Schema.Value.Format != "binary" {
+		t.Errorf("expected string/binary schema, got %v/%q", mt.Schema.Value.Type, mt.Schema.Value.Format)
+	}
+
+	doc := api.Spec().Paths.Find("/docs/{id}").Post.RequestBody.Value
+	raw := doc.Content.Get("application/json")
+	if raw == nil {
+		t.Fatal("expected application/json content for json.RawMessage")
+	}
+	if raw.Schema.Value.Type != nil {
+		t.Errorf("expected unconstrained schema for json.RawMessage, got %v", raw.Schema.Value.Type)
+	}
+	if _, ok := api.Spec().Components.Schemas["WebhookInput"]; ok {
+		t.Error("raw body input should not produce a body component schema")
+	}
+}
+
+func TestRawBody_invalidDeclarationsPanic(t *testing.T) {
+	tests := map[string]func(api *shiftapi.API){
+		"mixed with json": func(api *shiftapi.API) {
+			type In struct {
+				Name string `json:"name"`
+				Raw  []byte `body:""`
+			}
+			shiftapi.Handle(api, "POST /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+		"unsupported type": func(api *shiftapi.API) {
+			type In struct {
+				Raw string `body:""`
+			}
+			shiftapi.Handle(api, "POST /x", func(r *http.Request, in In) (*Empty, error) { return &Empty{}, nil })
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
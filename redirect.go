@@ -0,0 +1,154 @@
+package shiftapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// StatusCoder is implemented by response values that choose their own HTTP
+// status code at runtime. When a handler returns a StatusCoder with a non-zero
+// status, that status replaces the route's status and any status declared with
+// [WithResponse]. For 204 and 304 no body is written.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// RedirectResponse is a response with only a status code and a Location
+// header. Create one with [Redirect].
+type RedirectResponse struct {
+	Location string `header:"Location"`
+	status   int
+}
+
+// Redirect returns a response that redirects the client to location with the
+// given status code, typically 301, 302, 303, 307, or 308. The handler's
+// response type must be able to hold it, such as any, and the route should
+// declare the status with [WithRedirect] so that it is documented:
+//
+//	shiftapi.Handle(api, "POST /login", func(r *http.Request, in LoginInput) (any, error) {
+//	    if err := auth.Login(r.Context(), in); err != nil {
+//	        return nil, err
+//	    }
+//	    return shiftapi.Redirect("/dashboard", http.StatusSeeOther), nil
+//	}, shiftapi.WithRedirect(http.StatusSeeOther))
+//
+// Redirect panics if status is not a 3xx code.
+func Redirect(location string, status int) *RedirectResponse {
+	checkRedirectStatus(status)
+	return &RedirectResponse{Location: location, status: status}
+}
+
+// StatusCode implements [StatusCoder].
+func (r *RedirectResponse) StatusCode() int { return r.status }
+
+func (r *RedirectResponse) writeHeader(w http.ResponseWriter) {
+	w.Header().Set("Location", r.Location)
+	w.WriteHeader(r.status)
+}
+
+// StatusResponse is a response with only a status code and no body. Create
+// one with [Status].
+type StatusResponse struct {
+	status int
+}
+
+// Status returns a response that sends only the given status code, such as
+// 304 Not Modified. Like [Redirect], the handler's response type must be able
+// to hold it, and the status should be declared with [WithEmptyResponse].
+//
+// Status panics if status is not a three-digit code (100-999), which
+// [http.ResponseWriter.WriteHeader] would reject.
+func Status(status int) *StatusResponse {
+	checkStatus(status)
+	return &StatusResponse{status: status}
+}
+
+// checkRedirectStatus panics if status is not a 3xx code.
+func checkRedirectStatus(status int) {
+	if status < 300 || status > 399 {
+		panic(fmt.Sprintf("shiftapi: redirect status must be 3xx, got %d", status))
+	}
+}
+
+// checkStatus panics if status is not a valid HTTP status code.
+func checkStatus(status int) {
+	if status < 100 || status > 999 {
+		panic(fmt.Sprintf("shiftapi: status must be between 100 and 999, got %d", status))
+	}
+}
+
+// StatusCode implements [StatusCoder].
+func (s *StatusResponse) StatusCode() int { return s.status }
+
+func (s *StatusResponse) writeHeader(w http.ResponseWriter) {
+	w.WriteHeader(s.status)
+}
+
+// emptyResponse is implemented by the bodiless responses returned by
+// [Redirect] and [Status].
+type emptyResponse interface {
+	StatusCoder
+	writeHeader(w http.ResponseWriter)
+}
+
+// emptyResponseEntry is a bodiless response declared with [WithRedirect] or
+// [WithEmptyResponse].
+type emptyResponseEntry struct {
+	status   int
+	redirect bool // documents a required Location header
+}
+
+// WithRedirect documents that the route may respond with a [Redirect] using
+// any of the given status codes. Each status is documented with a Location
+// header and no body.
+func WithRedirect(statuses ...int) routeOptionFunc {
+	return func(cfg *routeConfig) {
+		for _, s := range statuses {
+			cfg.emptyResponses = append(cfg.emptyResponses, emptyResponseEntry{status: s, redirect: true})
+		}
+	}
+}
+
+// WithEmptyResponse documents that the route may respond with a bodiless
+// [Status] response using any of the given status codes, such as 304 Not
+// Modified.
+func WithEmptyResponse(statuses ...int) routeOptionFunc {
+	return func(cfg *routeConfig) {
+		for _, s := range statuses {
+			cfg.emptyResponses = append(cfg.emptyResponses, emptyResponseEntry{status: s})
+		}
+	}
+}
+
+// checkEmptyResponses panics if a handler with response type respType could
+// not return the declared bodiless responses, or if a declared status is
+// already used by another response of the route.
+func checkEmptyResponses(cfg *routeConfig, respType reflect.Type) {
+	if len(cfg.emptyResponses) == 0 {
+		return
+	}
+	taken := make(map[int]bool)
+	if respType.Kind() != reflect.Interface {
+		taken[cfg.status] = true
+	}
+	for _, e := range cfg.responses {
+		taken[e.status] = true
+	}
+	for _, e := range cfg.emptyResponses {
+		helper, name := reflect.TypeFor[*StatusResponse](), "WithEmptyResponse"
+		if e.redirect {
+			helper, name = reflect.TypeFor[*RedirectResponse](), "WithRedirect"
+			checkRedirectStatus(e.status)
+		} else {
+			checkStatus(e.status)
+		}
+		if !helper.AssignableTo(respType) {
+			panic(fmt.Sprintf("shiftapi: %s requires a response type that can hold %s, such as any; got %s", name, helper, respType))
+		}
+		if taken[e.status] {
+			panic(fmt.Sprintf("shiftapi: status %d declared more than once", e.status))
+		}
+		taken[e.status] = true
+	}
+}
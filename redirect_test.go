@@ -0,0 +1,197 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type LoginInput struct {
+	Next string `query:"next"`
+}
+
+type LoginPage struct {
+	Title string `json:"title"`
+}
+
+func login(r *http.Request, in LoginInput) (any, error) {
+	if in.Next != "" {
+		return shiftapi.Redirect(in.Next, http.StatusSeeOther), nil
+	}
+	return &LoginPage{Title: "Sign in"}, nil
+}
+
+func TestRedirect(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /login", login,
+		shiftapi.WithResponse[*LoginPage](http.StatusOK),
+		shiftapi.WithRedirect(http.StatusSeeOther),
+	)
+
+	resp := doRequest(t, api, http.MethodGet, "/login?next=/dashboard", "")
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Location"); got != "/dashboard" {
+		t.Errorf("expected Location /dashboard, got %q", got)
+	}
+	if got := readBody(t, resp); got != "" {
+		t.Errorf("expected empty body, got %q", got)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/login", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := decodeJSON[LoginPage](t, resp); got.Title != "Sign in" {
+		t.Errorf("unexpected body %+v", got)
+	}
+}
+
+func TestStatus_notModified(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /feed", func(r *http.Request, _ struct{}) (any, error) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			return shiftapi.Status(http.StatusNotModified), nil
+		}
+		return &LoginPage{Title: "feed"}, nil
+	}, shiftapi.WithEmptyResponse(http.StatusNotModified), shiftapi.WithResponseHeader("ETag", `"v1"`))
+
+	req := map[string]string{"If-None-Match": `"v1"`}
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/feed", "", req)
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got != `"v1"` {
+		t.Errorf("expected static ETag header, got %q", got)
+	}
+	if got := readBody(t, resp); got != "" {
+		t.Errorf("expected empty body, got %q", got)
+	}
+}
+
+type upsertResult struct {
+	ID      string `json:"id"`
+	created bool
+}
+
+func (u *upsertResult) StatusCode() int {
+	if u.created {
+		return http.StatusCreated
+	}
+	return 0
+}
+
+func TestStatusCoder(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "PUT /things/{id}", func(r *http.Request, _ struct{}) (*upsertResult, error) {
+		return &upsertResult{ID: r.PathValue("id"), created: r.PathValue("id") == "new"}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodPut, "/things/new", "{}")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if got := decodeJSON[map[string]string](t, resp); got["id"] != "new" {
+		t.Errorf("unexpected body %v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodPut, "/things/old", "{}")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected route status for a zero StatusCode, got %d", resp.StatusCode)
+	}
+}
+
+func TestRedirect_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /login", login,
+		shiftapi.WithResponse[*LoginPage](http.StatusOK),
+		shiftapi.WithRedirect(http.StatusFound, http.StatusSeeOther),
+		shiftapi.WithEmptyResponse(http.StatusNotModified),
+	)
+
+	op := api.Spec().Paths.Find("/login").Get
+	for _, code := range []string{"302", "303"} {
+		resp := op.Responses.Value(code)
+		if resp == nil {
+			t.Fatalf("expected %s response", code)
+		}
+		if resp.Value.Content != nil {
+			t.Errorf("%s: expected no content", code)
+		}
+		loc := resp.Value.Headers["Location"]
+		if loc == nil || !loc.Value.Required {
+			t.Errorf("%s: expected required Location header", code)
+		}
+	}
+	notModified := op.Responses.Value("304")
+	if notModified == nil || notModified.Value.Content != nil || notModified.Value.Headers["Location"] != nil {
+		t.Errorf("expected bare 304 response, got %+v", notModified)
+	}
+}
+
+func TestRedirect_invalidDeclarationsPanic(t *testing.T) {
+	tests := map[string]func(api *shiftapi.API){
+		"concrete response type": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", func(r *http.Request, _ struct{}) (*LoginPage, error) {
+				return nil, nil
+			}, shiftapi.WithRedirect(http.StatusFound))
+		},
+		"duplicate status": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", login,
+				shiftapi.WithResponse[*LoginPage](http.StatusOK),
+				shiftapi.WithEmptyResponse(http.StatusOK),
+			)
+		},
+		"non-3xx redirect": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", login, shiftapi.WithRedirect(http.StatusOK))
+		},
+		"invalid empty response status": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", login, shiftapi.WithEmptyResponse(42))
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
+
+func TestRedirect_invalidStatusPanics(t *testing.T) {
+	tests := map[string]func(){
+		"redirect with 200": func() { shiftapi.Redirect("/x", http.StatusOK) },
+		"redirect with 400": func() { shiftapi.Redirect("/x", http.StatusBadRequest) },
+		"redirect with 0":   func() { shiftapi.Redirect("/x", 0) },
+		"status below 100":  func() { shiftapi.Status(99) },
+		"status above 999":  func() { shiftapi.Status(1000) },
+		"status of zero":    func() { shiftapi.Status(0) },
+	}
+	for name, build := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("expected panic")
+				}
+				if msg, _ := r.(string); !strings.HasPrefix(msg, "shiftapi: ") {
+					t.Errorf("unexpected panic %v", r)
+				}
+			}()
+			build()
+		})
+	}
+
+	if got := shiftapi.Redirect("/x", http.StatusPermanentRedirect).StatusCode(); got != http.StatusPermanentRedirect {
+		t.Errorf("expected 308, got %d", got)
+	}
+	if got := shiftapi.Status(http.StatusNotModified).StatusCode(); got != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", got)
+	}
+}
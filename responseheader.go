@@ -2,17 +2,23 @@ package shiftapi
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	xmlNameType       = reflect.TypeFor[xml.Name]()
+)
 
-// hasRespHeaderFields reports whether the type has any exported fields with a `header` tag.
-// This is used for response types to determine if response headers need to be written.
+// hasRespHeaderFields reports whether the type has any exported fields with a
+// `header` or `cookie` tag. This is used for response types to determine if
+// response headers (including Set-Cookie) need to be written.
 func hasRespHeaderFields(t reflect.Type) bool {
 	for t != nil && t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -21,7 +27,7 @@ func hasRespHeaderFields(t reflect.Type) bool {
 		return false
 	}
 	for f := range t.Fields() {
-		if f.IsExported() && hasHeaderTag(f) {
+		if f.IsExported() && (hasHeaderTag(f) || hasCookieTag(f)) {
 			return true
 		}
 	}
@@ -29,9 +35,11 @@ func hasRespHeaderFields(t reflect.Type) bool {
 }
 
 // writeResponseHeaders extracts header-tagged fields from a response value
-// and sets them on the ResponseWriter. Fields are formatted as their string
-// representation. Only scalar types and pointer-to-scalar types are supported.
-func writeResponseHeaders(w http.ResponseWriter, resp any) {
+// and sets them on the ResponseWriter. Fields are formatted with the scalar
+// registry (registered format functions, [encoding.TextMarshaler], or their
+// string representation). Only scalar types and pointer-to-scalar types are
+// supported. Cookie-tagged fields are written as Set-Cookie headers.
+func writeResponseHeaders(w http.ResponseWriter, resp any, scalars scalarRegistry) {
 	rv := reflect.ValueOf(resp)
 	for rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
@@ -46,7 +54,16 @@ func writeResponseHeaders(w http.ResponseWriter, resp any) {
 	rt := rv.Type()
 	for i := range rt.NumField() {
 		field := rt.Field(i)
-		if !field.IsExported() || !hasHeaderTag(field) {
+		if !field.IsExported() {
+			continue
+		}
+		if hasCookieTag(field) {
+			if c := responseCookie(field, rv.Field(i), scalars); c != nil {
+				http.SetCookie(w, c)
+			}
+			continue
+		}
+		if !hasHeaderTag(field) {
 			continue
 		}
 
@@ -63,11 +80,12 @@ func writeResponseHeaders(w http.ResponseWriter, resp any) {
 
 		// Non-pointer fields are always sent (consistent with required
 		// semantics elsewhere in the framework).
-		w.Header().Set(name, fmt.Sprint(fv.Interface()))
+		w.Header().Set(name, scalars.format(fv))
 	}
 }
 
-// respEncoder strips header-tagged fields from a response before JSON encoding.
+// respEncoder strips header-tagged and cookie-tagged fields from a response
+// before JSON encoding.
 // The derived struct type is built once at registration time so that runtime
 // encoding uses a real struct — preserving omitempty, custom marshalers on
 // field types, embedded structs, and all other encoding/json behavior.
@@ -83,7 +101,7 @@ type respEncoder struct {
 }
 
 // newRespEncoder builds a derived struct type from t that excludes all
-// header-tagged fields. Returns nil if t is not a struct.
+// header-tagged and cookie-tagged fields. Returns nil if t is not a struct.
 //
 // If t (or *t) implements json.Marshaler, the encoder is returned with
 // customJSON set to true — the original value is encoded as-is so the
@@ -111,12 +129,22 @@ func newRespEncoder(t reflect.Type) *respEncoder {
 		if !f.IsExported() {
 			continue // skip unexported — can't copy via reflect, invisible to encoding/json
 		}
-		if hasHeaderTag(f) {
+		if hasHeaderTag(f) || hasCookieTag(f) {
 			continue
 		}
 		fields = append(fields, f)
 		mapping = append(mapping, i)
 	}
+	// The derived type is anonymous, so encoding/xml needs an XMLName to
+	// name the root element; it is appended after the mapped fields and left
+	// zero, so the tag gives the original type's name.
+	if _, ok := t.FieldByName("XMLName"); !ok && t.Name() != "" {
+		fields = append(fields, reflect.StructField{
+			Name: "XMLName",
+			Type: xmlNameType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"-" xml:"%s"`, t.Name())),
+		})
+	}
 
 	return &respEncoder{
 		derivedType:  reflect.StructOf(fields),
@@ -126,7 +154,7 @@ func newRespEncoder(t reflect.Type) *respEncoder {
 
 // encode returns a value suitable for JSON encoding. When the response type
 // has a custom MarshalJSON, it returns the original value unchanged. Otherwise
-// it copies non-header fields into the derived struct type.
+// it copies non-header, non-cookie fields into the derived struct type.
 func (e *respEncoder) encode(resp any) any {
 	if e.customJSON {
 		return resp
@@ -151,8 +179,9 @@ func (e *respEncoder) encode(resp any) any {
 }
 
 // generateRespHeaders builds OpenAPI header definitions from header-tagged
-// fields on a response struct type.
-func generateRespHeaders(t reflect.Type) openapi3.Headers {
+// fields on a response struct type. Cookie-tagged fields are collected into a
+// single Set-Cookie header whose description lists the cookie names.
+func (a *API) generateRespHeaders(t reflect.Type) openapi3.Headers {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -161,13 +190,32 @@ func generateRespHeaders(t reflect.Type) openapi3.Headers {
 	}
 
 	headers := make(openapi3.Headers)
+	var cookieNames []string
+	cookieRequired := false
 	for f := range t.Fields() {
-		if !f.IsExported() || !hasHeaderTag(f) {
+		if !f.IsExported() {
+			continue
+		}
+		if hasCookieTag(f) {
+			cookieNames = append(cookieNames, cookieFieldName(f))
+			if f.Type.Kind() != reflect.Pointer {
+				cookieRequired = true
+			}
+			continue
+		}
+		if !hasHeaderTag(f) {
 			continue
 		}
 
 		name := http.CanonicalHeaderKey(headerFieldName(f))
 		schema := scalarToOpenAPISchema(f.Type)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if a.scalars.isTextScalar(ft) {
+			schema.Value.Format = a.scalars.schemaFormat(ft)
+		}
 
 		required := f.Type.Kind() != reflect.Pointer
 		headers[name] = &openapi3.HeaderRef{
@@ -181,14 +229,30 @@ func generateRespHeaders(t reflect.Type) openapi3.Headers {
 			},
 		}
 	}
+	if len(cookieNames) > 0 {
+		headers["Set-Cookie"] = &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Name:        "Set-Cookie",
+					In:          "header",
+					Description: "Sets cookies: " + strings.Join(cookieNames, ", "),
+					Required:    cookieRequired,
+					Schema: &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: &openapi3.Types{"string"}},
+					},
+				},
+			},
+		}
+	}
 	if len(headers) == 0 {
 		return nil
 	}
 	return headers
 }
 
-// stripRespHeaderFields removes header-tagged fields from a response body
-// schema's Properties and Required slices so they don't appear in the JSON body.
+// stripRespHeaderFields removes header-tagged and cookie-tagged fields from a
+// response body schema's Properties and Required slices so they don't appear
+// in the JSON body.
 func stripRespHeaderFields(t reflect.Type, schema *openapi3.Schema) {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -197,7 +261,7 @@ func stripRespHeaderFields(t reflect.Type, schema *openapi3.Schema) {
 		return
 	}
 	for f := range t.Fields() {
-		if !f.IsExported() || !hasHeaderTag(f) {
+		if !f.IsExported() || !(hasHeaderTag(f) || hasCookieTag(f)) {
 			continue
 		}
 		jname := jsonFieldName(f)
@@ -0,0 +1,137 @@
+package shiftapi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// responseEntry is a success response declared with [WithResponse].
+type responseEntry struct {
+	status int
+	typ    reflect.Type
+}
+
+// WithResponse declares that the handler may return a value of type T, which
+// is sent with the given success status instead of the route's default. The
+// handler's response type must be an interface, such as any, that each
+// declared type satisfies:
+//
+//	type JobAccepted struct {
+//	    JobID string `json:"job_id"`
+//	}
+//
+//	func createReport(r *http.Request, in ReportInput) (any, error) {
+//	    if in.Async {
+//	        return &JobAccepted{JobID: enqueue(in)}, nil
+//	    }
+//	    return buildReport(in), nil
+//	}
+//
+//	shiftapi.Handle(api, "POST /reports", createReport,
+//	    shiftapi.WithResponse[*Report](http.StatusOK),
+//	    shiftapi.WithResponse[*JobAccepted](http.StatusAccepted),
+//	)
+//
+// The status is chosen by the dynamic type of the returned value; T and *T
+// both match. Values of undeclared types are sent with the route's status.
+// Each declared response is documented in the OpenAPI spec under its status,
+// so generated clients can narrow the result by status code. Header-tagged
+// fields of T are written as response headers, and no-body statuses such as
+// 204 require T to have no JSON body fields. Declare a distinct type for each
+// status.
+func WithResponse[T any](status int) routeOptionFunc {
+	t := reflect.TypeFor[T]()
+	return func(cfg *routeConfig) {
+		cfg.responses = append(cfg.responses, responseEntry{status: status, typ: t})
+	}
+}
+
+// responseVariant is how a declared response type is written.
+type responseVariant struct {
+	status  int
+	noBody  bool
+	respEnc *respEncoder
+}
+
+// responseLookup maps declared response types, and their pointer and element
+// types, to how they are written. Built once at route registration time.
+type responseLookup map[reflect.Type]*responseVariant
+
+// buildResponseLookup validates the declared responses against the handler's
+// response type and builds their lookup. It panics on declarations that the
+// handler could never return or that would be ambiguous.
+func buildResponseLookup(entries []responseEntry, respType reflect.Type) responseLookup {
+	if len(entries) == 0 {
+		return nil
+	}
+	if respType.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("shiftapi: WithResponse requires an interface response type such as any, got %s; use WithStatus to change the status of a single response type", respType))
+	}
+	lookup := make(responseLookup, len(entries)*2)
+	statuses := make(map[int]bool, len(entries))
+	for _, e := range entries {
+		if e.typ.Kind() == reflect.Interface {
+			panic(fmt.Sprintf("shiftapi: WithResponse type %s must be a concrete type", e.typ))
+		}
+		if !e.typ.AssignableTo(respType) {
+			panic(fmt.Sprintf("shiftapi: WithResponse type %s is not assignable to the handler's response type %s", e.typ, respType))
+		}
+		if statuses[e.status] {
+			panic(fmt.Sprintf("shiftapi: status %d declared more than once; declare a distinct status for each response type", e.status))
+		}
+		statuses[e.status] = true
+		if _, dup := lookup[e.typ]; dup {
+			panic(fmt.Sprintf("shiftapi: WithResponse type %s declared more than once", e.typ))
+		}
+		noBody := isNoBodyStatus(e.status)
+		if noBody {
+			checkNoBodyResponseType(e.status, e.typ)
+		}
+		v := &responseVariant{status: e.status, noBody: noBody}
+		if hasRespHeaderFields(e.typ) {
+			v.respEnc = newRespEncoder(e.typ)
+		}
+		lookup[e.typ] = v
+		if e.typ.Kind() == reflect.Pointer {
+			lookup[e.typ.Elem()] = v
+		} else {
+			lookup[reflect.PointerTo(e.typ)] = v
+		}
+	}
+	return lookup
+}
+
+// match returns how resp is written if its dynamic type was declared.
+func (l responseLookup) match(resp any) (*responseVariant, bool) {
+	if len(l) == 0 || resp == nil {
+		return nil, false
+	}
+	v, ok := l[reflect.TypeOf(resp)]
+	return v, ok
+}
+
+// hasBody reports whether any declared response carries a body.
+func (l responseLookup) hasBody() bool {
+	for _, v := range l {
+		if !v.noBody {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNoBodyResponseType panics if a response type used with a no-body
+// status code has JSON body fields.
+func checkNoBodyResponseType(status int, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for f := range t.Fields() {
+		if f.IsExported() && !hasHeaderTag(f) && !hasCookieTag(f) {
+			panic(fmt.Sprintf("shiftapi: status %d must not have a response body; response type %s has JSON body field %q — use struct{} or a header-only struct", status, t.Name(), f.Name))
+		}
+	}
+}
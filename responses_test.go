@@ -0,0 +1,156 @@
+package shiftapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type ReportInput struct {
+	Async bool `query:"async"`
+}
+
+type Report struct {
+	Title string `json:"title"`
+}
+
+type JobAccepted struct {
+	JobID    string `json:"job_id"`
+	Location string `header:"Location" json:"-"`
+}
+
+type ReportGone struct {
+	Reason string `header:"X-Reason"`
+}
+
+func reportHandler(r *http.Request, in ReportInput) (any, error) {
+	switch {
+	case r.URL.Query().Get("gone") != "":
+		return ReportGone{Reason: "expired"}, nil
+	case in.Async:
+		return &JobAccepted{JobID: "j1", Location: "/jobs/j1"}, nil
+	}
+	return &Report{Title: "done"}, nil
+}
+
+func TestWithResponse_statusByType(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /reports", reportHandler,
+		shiftapi.WithResponse[*Report](http.StatusOK),
+		shiftapi.WithResponse[*JobAccepted](http.StatusAccepted),
+		shiftapi.WithResponse[ReportGone](http.StatusNoContent),
+	)
+
+	resp := doRequest(t, api, http.MethodGet, "/reports", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := decodeJSON[Report](t, resp); got.Title != "done" {
+		t.Errorf("unexpected body %+v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/reports?async=true", "")
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Location"); got != "/jobs/j1" {
+		t.Errorf("expected Location header, got %q", got)
+	}
+	body := decodeJSON[map[string]any](t, resp)
+	if body["job_id"] != "j1" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if _, ok := body["Location"]; ok {
+		t.Error("header field should not be in the body")
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/reports?gone=1", "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Reason"); got != "expired" {
+		t.Errorf("expected X-Reason header, got %q", got)
+	}
+	if got := readBody(t, resp); got != "" {
+		t.Errorf("expected empty body, got %q", got)
+	}
+}
+
+func TestWithResponse_undeclaredTypeUsesRouteStatus(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /things", func(r *http.Request, _ struct{}) (any, error) {
+		return map[string]string{"ok": "yes"}, nil
+	}, shiftapi.WithStatus(http.StatusCreated), shiftapi.WithResponse[*JobAccepted](http.StatusAccepted))
+
+	resp := doRequest(t, api, http.MethodPost, "/things", "{}")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+}
+
+func TestWithResponse_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /reports", reportHandler,
+		shiftapi.WithResponse[*Report](http.StatusOK),
+		shiftapi.WithResponse[*JobAccepted](http.StatusAccepted),
+		shiftapi.WithResponse[ReportGone](http.StatusNoContent),
+	)
+
+	op := api.Spec().Paths.Find("/reports").Get
+	ok := op.Responses.Value("200")
+	if ok == nil || ok.Value.Content.Get("application/json").Schema.Ref != "#/components/schemas/Report" {
+		t.Fatalf("expected 200 to reference Report, got %+v", ok)
+	}
+	accepted := op.Responses.Value("202")
+	if accepted == nil || accepted.Value.Content.Get("application/json").Schema.Ref != "#/components/schemas/JobAccepted" {
+		t.Fatalf("expected 202 to reference JobAccepted, got %+v", accepted)
+	}
+	if accepted.Value.Headers["Location"] == nil {
+		t.Error("expected Location header on 202")
+	}
+	if _, ok := api.Spec().Components.Schemas["JobAccepted"].Value.Properties["Location"]; ok {
+		t.Error("header field should be stripped from the JobAccepted schema")
+	}
+	noContent := op.Responses.Value("204")
+	if noContent == nil || noContent.Value.Content != nil || noContent.Value.Headers["X-Reason"] == nil {
+		t.Fatalf("expected 204 with X-Reason header and no content, got %+v", noContent)
+	}
+}
+
+func TestWithResponse_invalidDeclarationsPanic(t *testing.T) {
+	tests := map[string]func(api *shiftapi.API){
+		"concrete response type": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", func(r *http.Request, _ struct{}) (*Report, error) {
+				return nil, nil
+			}, shiftapi.WithResponse[*Report](http.StatusCreated))
+		},
+		"not assignable": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", func(r *http.Request, _ struct{}) (error, error) {
+				return nil, nil
+			}, shiftapi.WithResponse[*Report](http.StatusAccepted))
+		},
+		"duplicate status": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", reportHandler,
+				shiftapi.WithResponse[*Report](http.StatusOK),
+				shiftapi.WithResponse[*JobAccepted](http.StatusOK),
+			)
+		},
+		"interface type": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", reportHandler, shiftapi.WithResponse[error](http.StatusOK))
+		},
+		"body on no-content": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /x", reportHandler, shiftapi.WithResponse[*Report](http.StatusNoContent))
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
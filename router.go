@@ -14,4 +14,9 @@ type routerData struct {
 	errors            []errorEntry                      // accumulated errors from API globals + group chain
 	middleware        []func(http.Handler) http.Handler // accumulated middleware from group chain
 	staticRespHeaders []staticResponseHeader            // accumulated static response headers from group chain
+	decodeMode        decodeMode                        // accumulated JSON decoding flags from group chain
+	maxBodySize       int64                             // innermost request body limit from group chain, 0 if none
+	maxDecompressed   int64                             // innermost decompressed body limit from group chain, 0 if none
+	compression       *compressionConfig                // innermost response compression from group chain, nil if none
+	security          *securityConfig                   // innermost security requirements from group chain, nil if none
 }
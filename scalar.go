@@ -0,0 +1,154 @@
+package shiftapi
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+	netIPType           = reflect.TypeFor[net.IP]()
+	netipAddrType       = reflect.TypeFor[netip.Addr]()
+	netipPrefixType     = reflect.TypeFor[netip.Prefix]()
+)
+
+// ScalarType describes how a type that is not a Go primitive is bound from
+// and written to text in path, query, header, cookie, and form fields. See
+// [WithScalarType].
+type ScalarType[T any] struct {
+	// Parse converts a raw parameter value to T. When nil, T must implement
+	// [encoding.TextUnmarshaler].
+	Parse func(string) (T, error)
+	// Format converts T to text for response headers and cookies. When nil,
+	// [encoding.TextMarshaler] or fmt formatting is used.
+	Format func(T) string
+	// SchemaFormat is the OpenAPI string format (e.g. "uuid", "ipv4")
+	// documented for fields of type T.
+	SchemaFormat string
+}
+
+// WithScalarType registers parse and format functions for a type that does
+// not implement [encoding.TextUnmarshaler], or overrides the documented
+// schema format of one that does. Registered types are bound like strings in
+// path, query, header, cookie, and form fields and documented as
+// {type: string, format: SchemaFormat}.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithScalarType(shiftapi.ScalarType[decimal.Decimal]{
+//	        Parse:        decimal.NewFromString,
+//	        Format:       decimal.Decimal.String,
+//	        SchemaFormat: "decimal",
+//	    }),
+//	    shiftapi.WithScalarType(shiftapi.ScalarType[netip.Addr]{SchemaFormat: "ipv4"}),
+//	)
+//
+// WithScalarType panics if Parse is nil and T does not implement
+// [encoding.TextUnmarshaler], since such fields could never be bound.
+func WithScalarType[T any](st ScalarType[T]) apiOptionFunc {
+	return func(api *API) {
+		t := reflect.TypeFor[T]()
+		if st.Parse == nil && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			panic(fmt.Sprintf("shiftapi: WithScalarType[%s] requires Parse, since %s does not implement encoding.TextUnmarshaler", t, t))
+		}
+		c := &scalarCodec{schemaFormat: st.SchemaFormat}
+		if st.Parse != nil {
+			c.parse = func(s string) (any, error) { return st.Parse(s) }
+		}
+		if st.Format != nil {
+			c.format = func(v any) string { return st.Format(v.(T)) }
+		}
+		api.scalars[t] = c
+	}
+}
+
+// scalarCodec is the type-erased form of a registered [ScalarType].
+type scalarCodec struct {
+	parse        func(string) (any, error)
+	format       func(any) string
+	schemaFormat string
+}
+
+// scalarRegistry maps types registered via WithScalarType to their codecs.
+type scalarRegistry map[reflect.Type]*scalarCodec
+
+// setValue parses raw into v. Registered parse functions take precedence,
+// then [encoding.TextUnmarshaler], then the primitive kinds handled by
+// setScalarValue.
+func (s scalarRegistry) setValue(v reflect.Value, raw string) error {
+	if c, ok := s[v.Type()]; ok && c.parse != nil {
+		val, err := c.parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: %w", raw, err)
+		}
+		v.Set(reflect.ValueOf(val))
+		return nil
+	}
+	return setScalarValue(v, raw)
+}
+
+// format renders v as text for response headers and cookies. Registered
+// format functions take precedence, then [encoding.TextMarshaler], then fmt.
+func (s scalarRegistry) format(v reflect.Value) string {
+	if c, ok := s[v.Type()]; ok && c.format != nil {
+		return c.format(v.Interface())
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// isTextScalar reports whether t is bound from a single text value even
+// though it is not a Go primitive: registered types and types whose pointer
+// implements [encoding.TextUnmarshaler].
+func (s scalarRegistry) isTextScalar(t reflect.Type) bool {
+	if _, ok := s[t]; ok {
+		return true
+	}
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isListType reports whether a field of type t is bound from a list of
+// values: a slice that is not itself a text scalar, such as net.IP.
+func (s scalarRegistry) isListType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !s.isTextScalar(t)
+}
+
+// encodesAsString reports whether a text scalar type is serialized as a JSON
+// string: registered types, and types implementing [encoding.TextMarshaler]
+// without a custom [json.Marshaler].
+func (s scalarRegistry) encodesAsString(t reflect.Type) bool {
+	if _, ok := s[t]; ok {
+		return true
+	}
+	return t.Implements(textMarshalerType) && !t.Implements(jsonMarshalerType)
+}
+
+// schemaFormat returns the OpenAPI string format for a text scalar type: the
+// registered SchemaFormat, or a well-known format for standard types.
+func (s scalarRegistry) schemaFormat(t reflect.Type) string {
+	if c, ok := s[t]; ok && c.schemaFormat != "" {
+		return c.schemaFormat
+	}
+	switch {
+	case t == timeType:
+		return "date-time"
+	case t == netipAddrType || t == netIPType:
+		// Either family; JSON Schema only defines ipv4 and ipv6.
+		return "ip"
+	case t == netipPrefixType:
+		return "cidr"
+	case t.Name() == "UUID" && t.Kind() == reflect.Array && t.Len() == 16:
+		// github.com/google/uuid, github.com/gofrs/uuid, and similar.
+		return "uuid"
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+package shiftapi_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fcjr/shiftapi"
+)
+
+// UUID is a minimal stand-in for github.com/google/uuid.UUID.
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func (u *UUID) UnmarshalText(b []byte) error {
+	s := strings.ReplaceAll(string(b), "-", "")
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != 16 {
+		return fmt.Errorf("invalid UUID %q", b)
+	}
+	copy(u[:], raw)
+	return nil
+}
+
+// Cents is a foreign-style type without text marshaling, registered via
+// WithScalarType.
+type Cents struct {
+	Amount int64
+}
+
+var centsScalar = shiftapi.ScalarType[Cents]{
+	Parse: func(s string) (Cents, error) {
+		n, err := strconv.ParseInt(strings.TrimSuffix(s, "c"), 10, 64)
+		return Cents{Amount: n}, err
+	},
+	Format:       func(c Cents) string { return strconv.FormatInt(c.Amount, 10) + "c" },
+	SchemaFormat: "cents",
+}
+
+type ScalarInput struct {
+	ID    UUID         `path:"id"`
+	Since time.Time    `query:"since"`
+	IPs   []netip.Addr `query:"ip"`
+	Net   netip.Prefix `query:"net"`
+	Peer  net.IP       `header:"X-Peer"`
+	Price *Cents       `query:"price"`
+	When  *time.Time   `header:"X-When"`
+}
+
+type ScalarResult struct {
+	Expires time.Time `header:"Expires-At"`
+	Price   Cents     `header:"X-Price"`
+	ID      UUID      `json:"id"`
+	Origin  net.IP    `json:"origin"`
+	Since   string    `json:"since"`
+	IPs     []string  `json:"ips"`
+	Price2  int64     `json:"price"`
+	When    string    `json:"when"`
+}
+
+func scalarHandler(r *http.Request, in ScalarInput) (*ScalarResult, error) {
+	res := &ScalarResult{
+		Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Price:   Cents{Amount: 250},
+		ID:      in.ID,
+		Since:   in.Since.Format(time.RFC3339),
+	}
+	for _, ip := range in.IPs {
+		res.IPs = append(res.IPs, ip.String())
+	}
+	if in.Price != nil {
+		res.Price2 = in.Price.Amount
+	}
+	if in.When != nil {
+		res.When = in.When.Format(time.RFC3339)
+	}
+	return res, nil
+}
+
+func TestScalar_bindsTextUnmarshalerAndRegisteredTypes(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithScalarType(centsScalar))
+	shiftapi.Handle(api, "GET /items/{id}", scalarHandler)
+
+	req := "/items/0123456789abcdef0123456789abcdef?since=2024-05-06T07:08:09Z&ip=10.0.0.1&ip=::1&price=99c"
+	r := doRequestWithHeaders(t, api, http.MethodGet, req, "", map[string]string{"X-When": "2025-01-01T00:00:00Z"})
+	if r.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", r.StatusCode, readBody(t, r))
+	}
+	if got := r.Header.Get("Expires-At"); got != "2030-01-02T03:04:05Z" {
+		t.Errorf("expected TextMarshaler-formatted header, got %q", got)
+	}
+	if got := r.Header.Get("X-Price"); got != "250c" {
+		t.Errorf("expected registered Format for header, got %q", got)
+	}
+	got := decodeJSON[map[string]any](t, r)
+	if got["id"] != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("unexpected id: %v", got["id"])
+	}
+	if got["since"] != "2024-05-06T07:08:09Z" {
+		t.Errorf("unexpected since: %v", got["since"])
+	}
+	if ips, _ := got["ips"].([]any); len(ips) != 2 || ips[0] != "10.0.0.1" || ips[1] != "::1" {
+		t.Errorf("unexpected ips: %v", got["ips"])
+	}
+	if got["price"] != float64(99) {
+		t.Errorf("unexpected price: %v", got["price"])
+	}
+	if got["when"] != "2025-01-01T00:00:00Z" {
+		t.Errorf("unexpected when: %v", got["when"])
+	}
+
+	r = doRequestWithHeaders(t, api, http.MethodGet, "/items/0123456789abcdef0123456789abcdef?net=10.0.0.0/8", "", map[string]string{"X-Peer": "192.0.2.1"})
+	if r.StatusCode != http.StatusOK {
+		t.Errorf("expected netip.Prefix and net.IP to bind, got %d: %s", r.StatusCode, readBody(t, r))
+	}
+	r = doRequest(t, api, http.MethodGet, "/items/0123456789abcdef0123456789abcdef?net=10.0.0.1", "")
+	if r.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid prefix, got %d", r.StatusCode)
+	}
+}
+
+func TestScalar_netIPQuery(t *testing.T) {
+	type RouteInput struct {
+		GW   net.IP   `query:"gw"`
+		Hops []net.IP `query:"hop,style=comma"`
+	}
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /route", func(r *http.Request, in RouteInput) (map[string]any, error) {
+		return map[string]any{"gw": in.GW.String(), "hops": len(in.Hops), "last": in.Hops[len(in.Hops)-1].String()}, nil
+	})
+
+	resp := doRequest(t, api, http.MethodGet, "/route?gw=10.0.0.1&hop=192.0.2.1,2001:db8::1", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected net.IP query fields to bind, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	got := decodeJSON[map[string]any](t, resp)
+	if got["gw"] != "10.0.0.1" || got["hops"] != float64(2) || got["last"] != "2001:db8::1" {
+		t.Errorf("unexpected binding %v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/route?gw=not-an-ip&hop=192.0.2.1", "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid IP, got %d", resp.StatusCode)
+	}
+
+	for _, p := range api.Spec().Paths.Find("/route").Get.Parameters {
+		s := p.Value.Schema.Value
+		switch p.Value.Name {
+		case "gw":
+			if !s.Type.Is("string") || s.Format != "ip" {
+				t.Errorf("expected gw documented as string/ip, got %v/%q", s.Type, s.Format)
+			}
+		case "hop":
+			if !s.Type.Is("array") || !s.Items.Value.Type.Is("string") || s.Items.Value.Format != "ip" {
+				t.Errorf("expected hop documented as an array of string/ip, got %+v", s)
+			}
+		}
+	}
+}
+
+func TestScalar_parseErrorsReturn400(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithScalarType(centsScalar))
+	shiftapi.Handle(api, "GET /items/{id}", scalarHandler)
+	for _, path := range []string{
+		"/items/not-a-uuid",
+		"/items/0123456789abcdef0123456789abcdef?since=yesterday",
+		"/items/0123456789abcdef0123456789abcdef?ip=999.1.1.1",
+		"/items/0123456789abcdef0123456789abcdef?price=lots",
+	} {
+		resp := doRequest(t, api, http.MethodGet, path, "")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestScalar_specFormats(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithScalarType(centsScalar))
+	shiftapi.Handle(api, "GET /items/{id}", scalarHandler)
+	op := api.Spec().Paths.Find("/items/{id}").Get
+
+	formats := map[string]string{}
+	for _, p := range op.Parameters {
+		s := p.Value.Schema.Value
+		if s.Type.Is("array") {
+			s = s.Items.Value
+		}
+		if !s.Type.Is("string") {
+			t.Errorf("%s: expected string schema, got %v", p.Value.Name, s.Type)
+		}
+		formats[p.Value.Name] = s.Format
+	}
+	want := map[string]string{"id": "uuid", "since": "date-time", "ip": "ip", "net": "cidr", "X-Peer": "ip", "price": "cents", "X-When": "date-time"}
+	for name, f := range want {
+		if formats[name] != f {
+			t.Errorf("%s: expected format %q, got %q", name, f, formats[name])
+		}
+	}
+
+	resp := op.Responses.Status(http.StatusOK).Value
+	if f := resp.Headers["Expires-At"].Value.Schema.Value.Format; f != "date-time" {
+		t.Errorf("expected Expires-At format date-time, got %q", f)
+	}
+	if f := resp.Headers["X-Price"].Value.Schema.Value.Format; f != "cents" {
+		t.Errorf("expected X-Price format cents, got %q", f)
+	}
+
+	id := api.Spec().Components.Schemas["ScalarResult"].Value.Properties["id"].Value
+	if !id.Type.Is("string") || id.Format != "uuid" {
+		t.Errorf("expected JSON body UUID as string/uuid, got %v/%q", id.Type, id.Format)
+	}
+	origin := api.Spec().Components.Schemas["ScalarResult"].Value.Properties["origin"].Value
+	if !origin.Type.Is("string") || origin.Format != "ip" {
+		t.Errorf("expected JSON body net.IP as string/ip, got %v/%q", origin.Type, origin.Format)
+	}
+}
+
+func TestScalar_registeringWithoutParsePanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected panic for a scalar type that cannot be parsed")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "requires Parse") {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	shiftapi.New(shiftapi.WithScalarType(shiftapi.ScalarType[Cents]{SchemaFormat: "cents"}))
+}
+
+func TestScalar_formatOnlyRegistrationForTextUnmarshaler(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithScalarType(shiftapi.ScalarType[UUID]{SchemaFormat: "uuid-hex"}))
+	shiftapi.Handle(api, "GET /things/{id}", func(r *http.Request, in struct {
+		ID UUID `path:"id"`
+	}) (*Empty, error) {
+		return &Empty{}, nil
+	})
+	if f := api.Spec().Paths.Find("/things/{id}").Get.Parameters[0].Value.Schema.Value.Format; f != "uuid-hex" {
+		t.Errorf("expected the registered format, got %q", f)
+	}
+}
@@ -2,10 +2,12 @@ package shiftapi
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -20,24 +22,42 @@ type schemaInput struct {
 	pathType           reflect.Type
 	queryType          reflect.Type
 	headerType         reflect.Type
+	cookieType         reflect.Type
 	bodyType           reflect.Type
 	outType            reflect.Type
 	hasRespHeader      bool
 	noBody             bool
 	hasForm            bool
 	formType           reflect.Type
+	strictBody         bool                 // request body schemas disallow additional properties
+	maxBodySize        int64                // request body limit, 0 if none
+	decompress         bool                 // request bodies are decoded by Content-Encoding
+	rawBody            *reflect.StructField // body-tagged raw body field
 	info               *RouteInfo
 	status             int
 	errors             []errorEntry
 	staticHeaders      []staticResponseHeader
 	contentType        string
 	responseSchemaType reflect.Type
-	eventVariants      []SSEEventVariant // SSE event variants for oneOf schema
+	eventVariants      []SSEEventVariant    // SSE event variants for oneOf schema
+	responses          []responseEntry      // additional success responses from WithResponse
+	emptyResponses     []emptyResponseEntry // bodiless responses from WithRedirect and WithEmptyResponse
+	etag               bool                 // responses carry an ETag validator
+	lastModified       bool                 // responses carry a Last-Modified validator
+	security           *securityConfig      // security requirements, nil if none
+	requestExamples    []any                // request body examples from WithRequestExample
+	responseExamples   []responseExample    // response body examples from WithResponseExample
+	preconditions      bool                 // the route checks preconditions with WithPreconditions
 }
 
 func (a *API) updateSchema(si schemaInput) error {
+	opID := operationID(si.method, si.path)
+	if si.info != nil && si.info.OperationID != "" {
+		opID = si.info.OperationID
+	}
+	a.claimOperationID(opID, si.method+" "+si.path)
 	op := &openapi3.Operation{
-		OperationID: operationID(si.method, si.path),
+		OperationID: opID,
 		Responses:   openapi3.NewResponses(),
 	}
 
@@ -60,25 +80,26 @@ func (a *API) updateSchema(si schemaInput) error {
 	// Path parameters
 	for _, match := range pathParamRe.FindAllStringSubmatch(si.path, -1) {
 		name := match[1]
-		var schema *openapi3.SchemaRef
+		param := &openapi3.Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+		}
 		if field, ok := pathFields[name]; ok {
-			schema = scalarToOpenAPISchema(field.Type)
-			_ = a.schemaCustomizer(name, field.Type, field.Tag, schema.Value)
+			param.Schema = scalarToOpenAPISchema(field.Type)
+			param.Description = fieldDescription(field.Tag)
+			if err := a.schemaCustomizer(name, field.Type, field.Tag, param.Schema.Value); err != nil {
+				return err
+			}
+			a.recordParamDoc(param, si.pathType, field)
 		} else {
-			schema = &openapi3.SchemaRef{
+			param.Schema = &openapi3.SchemaRef{
 				Value: &openapi3.Schema{
 					Type: &openapi3.Types{"string"},
 				},
 			}
 		}
-		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
-			Value: &openapi3.Parameter{
-				Name:     name,
-				In:       "path",
-				Required: true,
-				Schema:   schema,
-			},
-		})
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
 	}
 
 	// Query parameters
@@ -99,20 +120,26 @@ func (a *API) updateSchema(si schemaInput) error {
 		op.Parameters = append(op.Parameters, headerParams...)
 	}
 
+	// Cookie parameters
+	if si.cookieType != nil {
+		cookieParams, err := a.generateCookieParams(si.cookieType)
+		if err != nil {
+			return err
+		}
+		op.Parameters = append(op.Parameters, cookieParams...)
+	}
+
 	// Response schema
 	statusStr := fmt.Sprintf("%d", si.status)
 
-	// Build response header definitions from header-tagged fields on the output type
-	// and static response headers from WithResponseHeader.
-	var respHeaders openapi3.Headers
-	if si.hasRespHeader && si.outType != nil {
-		respHeaders = generateRespHeaders(si.outType)
-	}
+	// Build response header definitions from static response headers from
+	// WithResponseHeader and header-tagged fields on the output type.
+	var staticHeaders openapi3.Headers
 	for _, h := range si.staticHeaders {
-		if respHeaders == nil {
-			respHeaders = make(openapi3.Headers)
+		if staticHeaders == nil {
+			staticHeaders = make(openapi3.Headers)
 		}
-		respHeaders[h.name] = &openapi3.HeaderRef{
+		staticHeaders[h.name] = &openapi3.HeaderRef{
 			Value: &openapi3.Header{
 				Parameter: openapi3.Parameter{
 					Name:     h.name,
@@ -128,6 +155,12 @@ func (a *API) updateSchema(si schemaInput) error {
 		}
 	}
 
+	var respHeaders openapi3.Headers
+	if si.hasRespHeader && si.outType != nil {
+		respHeaders = a.generateRespHeaders(si.outType)
+	}
+	respHeaders = mergeHeaders(mergeHeaders(respHeaders, validatorHeaders(si.outType)), staticHeaders)
+
 	if si.noBody {
 		// No-body status codes (204, 304) — emit response with description
 		// and optional headers, but no content.
@@ -225,52 +258,75 @@ func (a *API) updateSchema(si schemaInput) error {
 			resp.Headers = respHeaders
 		}
 		op.Responses.Set(statusStr, &openapi3.ResponseRef{Value: resp})
-	} else {
-		outSchema, err := a.generateSchemaRef(si.outType)
+	} else if si.outType != nil || len(si.responses) == 0 {
+		// An interface response type with WithResponse variants has no
+		// schema of its own; the variants below document it.
+		resp, err := a.typedResponse(si.outType, si.status, si.hasRespHeader, respHeaders)
 		if err != nil {
 			return err
 		}
-		if si.hasRespHeader && outSchema != nil {
-			stripRespHeaderFields(si.outType, outSchema.Value)
+		if resp != nil {
+			op.Responses.Set(statusStr, &openapi3.ResponseRef{Value: resp})
 		}
+	}
 
-		if outSchema != nil {
-			resp := &openapi3.Response{
-				Description: new(http.StatusText(si.status)),
-			}
-			if outSchema.Ref != "" && len(outSchema.Value.Properties) > 0 {
-				// Named object schema — reference by $ref.
-				resp.Content = map[string]*openapi3.MediaType{
-					"application/json": {
-						Schema: &openapi3.SchemaRef{
-							Ref: fmt.Sprintf("#/components/schemas/%s", outSchema.Ref),
-						},
-					},
-				}
-				a.spec.Components.Schemas[outSchema.Ref] = &openapi3.SchemaRef{
-					Value: outSchema.Value,
-				}
-			} else if outSchema.Value.Type != nil && !outSchema.Value.Type.Is("object") {
-				// Non-object schema (array, primitive) — inline directly.
-				// Register any nested object schemas (e.g. array items) in components.
-				a.registerNestedSchemas(outSchema)
-				resp.Content = map[string]*openapi3.MediaType{
-					"application/json": {
-						Schema: outSchema,
-					},
-				}
+	// Additional success responses from WithResponse.
+	for _, v := range si.responses {
+		var headers openapi3.Headers
+		if hasRespHeaderFields(v.typ) {
+			headers = a.generateRespHeaders(v.typ)
+		}
+		headers = mergeHeaders(mergeHeaders(headers, validatorHeaders(v.typ)), staticHeaders)
+		resp := &openapi3.Response{Description: new(http.StatusText(v.status))}
+		if !isNoBodyStatus(v.status) {
+			typed, err := a.typedResponse(v.typ, v.status, hasRespHeaderFields(v.typ), nil)
+			if err != nil {
+				return err
 			}
-			if len(respHeaders) > 0 {
-				resp.Headers = respHeaders
+			if typed != nil {
+				resp = typed
 			}
-			op.Responses.Set(statusStr, &openapi3.ResponseRef{Value: resp})
-		} else if len(respHeaders) > 0 {
-			op.Responses.Set(statusStr, &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: new(http.StatusText(si.status)),
-					Headers:     respHeaders,
-				},
-			})
+		}
+		if len(headers) > 0 {
+			resp.Headers = headers
+		}
+		op.Responses.Set(fmt.Sprintf("%d", v.status), &openapi3.ResponseRef{Value: resp})
+	}
+
+	// Conditional requests: 304 for reads whose responses carry validators,
+	// and 412 for writes that check preconditions with WithPreconditions.
+	if isSafeMethod(si.method) && (si.etag || si.lastModified) {
+		op.Parameters = append(op.Parameters, conditionalParams(si.method, si.etag, si.lastModified)...)
+		op.Responses.Set("304", &openapi3.ResponseRef{Value: &openapi3.Response{
+			Description: new(http.StatusText(http.StatusNotModified)),
+		}})
+	} else if si.preconditions {
+		op.Parameters = append(op.Parameters, conditionalParams(si.method, true, true)...)
+		op.Responses.Set("412", messageResponseRef("Precondition Failed"))
+	}
+
+	// Redirects and other bodiless responses from WithRedirect and WithEmptyResponse.
+	for _, e := range si.emptyResponses {
+		var headers openapi3.Headers
+		if e.redirect {
+			headers = openapi3.Headers{"Location": locationHeaderRef()}
+		}
+		resp := &openapi3.Response{Description: new(http.StatusText(e.status))}
+		if headers = mergeHeaders(headers, staticHeaders); len(headers) > 0 {
+			resp.Headers = headers
+		}
+		op.Responses.Set(fmt.Sprintf("%d", e.status), &openapi3.ResponseRef{Value: resp})
+	}
+
+	// Security requirements, with 401 for authenticated operations and 403
+	// for those that require scopes. WithError can override either below.
+	if si.security != nil {
+		authenticated, scoped := a.addSecurityRequirements(op, si.security)
+		if authenticated {
+			op.Responses.Set("401", messageResponseRef("Unauthorized"))
+		}
+		if scoped {
+			op.Responses.Set("403", messageResponseRef("Forbidden"))
 		}
 	}
 
@@ -299,8 +355,12 @@ func (a *API) updateSchema(si schemaInput) error {
 
 	// Request body schema
 	if si.hasForm {
-		// multipart/form-data request body
-		formSchema, formEncoding := a.generateFormSchema(si.formType)
+		// multipart/form-data request body, plus application/x-www-form-urlencoded
+		// when the form has no file fields.
+		formSchema, formEncoding, err := a.generateFormSchema(si.formType)
+		if err != nil {
+			return err
+		}
 		mediaType := &openapi3.MediaType{
 			Schema: &openapi3.SchemaRef{
 				Value: formSchema,
@@ -309,11 +369,37 @@ func (a *API) updateSchema(si schemaInput) error {
 		if formEncoding != nil {
 			mediaType.Encoding = formEncoding
 		}
+		content := map[string]*openapi3.MediaType{
+			"multipart/form-data": mediaType,
+		}
+		if !hasFileFields(si.formType) {
+			content["application/x-www-form-urlencoded"] = &openapi3.MediaType{
+				Schema: &openapi3.SchemaRef{
+					Value: formSchema,
+				},
+			}
+		}
 		op.RequestBody = &openapi3.RequestBodyRef{
 			Value: &openapi3.RequestBody{
 				Required: true,
-				Content: map[string]*openapi3.MediaType{
-					"multipart/form-data": mediaType,
+				Content:  content,
+			},
+		}
+	} else if si.rawBody != nil {
+		// Raw body: documented under the field's media type. json.RawMessage
+		// accepts any JSON value; other raw bodies are binary.
+		bodySchema := &openapi3.Schema{}
+		if si.rawBody.Type != jsonRawMessageType {
+			bodySchema.Type = &openapi3.Types{"string"}
+			bodySchema.Format = "binary"
+		}
+		op.RequestBody = &openapi3.RequestBodyRef{
+			Value: &openapi3.RequestBody{
+				Required: true,
+				Content: openapi3.Content{
+					rawBodyMediaType(*si.rawBody): &openapi3.MediaType{
+						Schema: &openapi3.SchemaRef{Value: bodySchema},
+					},
 				},
 			},
 		}
@@ -323,23 +409,30 @@ func (a *API) updateSchema(si schemaInput) error {
 			return err
 		}
 		if inSchema != nil {
-			// Strip query-tagged, header-tagged, and path-tagged fields from the body schema
+			// Strip query-tagged, header-tagged, cookie-tagged, and path-tagged fields from the body schema
 			stripQueryFields(si.bodyType, inSchema.Value)
 			stripHeaderFields(si.bodyType, inSchema.Value)
+			stripCookieFields(si.bodyType, inSchema.Value)
 			stripPathFields(si.bodyType, inSchema.Value)
+			if si.strictBody && len(inSchema.Value.Properties) > 0 {
+				// The component is shared with every other route using the
+				// type, so strict routes document a closed copy under its
+				// own name.
+				inSchema = &openapi3.SchemaRef{
+					Ref:   inSchema.Ref + "Strict",
+					Value: cloneObjectSchemas(inSchema.Value),
+				}
+				closeObjectSchemas(inSchema.Value)
+			}
 
 			if len(inSchema.Value.Properties) > 0 {
 				// Named body schema with properties
-				content := make(map[string]*openapi3.MediaType)
-				content["application/json"] = &openapi3.MediaType{
-					Schema: &openapi3.SchemaRef{
-						Ref: fmt.Sprintf("#/components/schemas/%s", inSchema.Ref),
-					},
-				}
 				op.RequestBody = &openapi3.RequestBodyRef{
 					Value: &openapi3.RequestBody{
 						Required: true,
-						Content:  content,
+						Content: a.codecContent(&openapi3.SchemaRef{
+							Ref: fmt.Sprintf("#/components/schemas/%s", inSchema.Ref),
+						}),
 					},
 				}
 				a.spec.Components.Schemas[inSchema.Ref] = &openapi3.SchemaRef{
@@ -352,25 +445,56 @@ func (a *API) updateSchema(si schemaInput) error {
 				op.RequestBody = &openapi3.RequestBodyRef{
 					Value: &openapi3.RequestBody{
 						Required: true,
-						Content: map[string]*openapi3.MediaType{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{
-									Value: &openapi3.Schema{
-										Type: &openapi3.Types{"object"},
-									},
-								},
-							},
-						},
+						Content: a.codecContent(&openapi3.SchemaRef{
+							Value: emptyBodySchema(si.strictBody),
+						}),
 					},
 				}
 			}
 		}
 	}
 
+	// Body size limits, documented on operations that accept a body. A
+	// decompressed body over its limit, and a file over its form field's
+	// maxsize or maxfiles, are also rejected with 413.
+	acceptsBody := op.RequestBody != nil || si.method == http.MethodPost || si.method == http.MethodPut || si.method == http.MethodPatch
+	if (si.maxBodySize > 0 || si.decompress) && acceptsBody || si.hasForm && hasFileLimits(si.formType) {
+		if _, ok := a.spec.Components.Schemas["RequestTooLargeError"]; !ok {
+			a.spec.Components.Schemas["RequestTooLargeError"] = messageOnlySchemaRef()
+		}
+		op.Responses.Set("413", errorResponseRef("Request Entity Too Large", "RequestTooLargeError"))
+	}
+
+	// Request decompression: the accepted encodings, and 415 for the rest.
+	if si.decompress && acceptsBody {
+		op.Parameters = append(op.Parameters, contentEncodingParam(a.contentDecoders))
+		op.Responses.Set("415", messageResponseRef("Unsupported Media Type"))
+	}
+
+	// Content negotiation failures, documented once codecs beyond JSON are registered.
+	if len(a.codecs) > 1 {
+		if op.RequestBody != nil && !si.hasForm && si.rawBody == nil {
+			op.Responses.Set("415", messageResponseRef("Unsupported Media Type"))
+		}
+		if !si.noBody && si.contentType == "" {
+			op.Responses.Set("406", messageResponseRef("Not Acceptable"))
+		}
+	}
+
 	if si.info != nil {
 		op.Summary = si.info.Summary
 		op.Description = si.info.Description
 		op.Tags = si.info.Tags
+		if d := si.info.Deprecation; d != nil {
+			op.Deprecated = true
+			if !d.Sunset.IsZero() {
+				op.Extensions = map[string]any{"x-sunset": d.Sunset.UTC().Format(time.RFC3339)}
+			}
+		}
+	}
+
+	if err := a.addExamples(op, si); err != nil {
+		return err
 	}
 
 	pathItem := a.spec.Paths.Find(si.path)
@@ -427,6 +551,18 @@ func operationID(method, path string) string {
 	return method + strings.Join(parts, "")
 }
 
+// claimOperationID reserves an operation ID for the given route, panicking if
+// another route already uses it.
+func (a *API) claimOperationID(id, route string) {
+	if existing, ok := a.operationIDs[id]; ok {
+		panic(fmt.Sprintf("shiftapi: duplicate operation ID %q for %s, already used by %s", id, route, existing))
+	}
+	if a.operationIDs == nil {
+		a.operationIDs = make(map[string]string)
+	}
+	a.operationIDs[id] = route
+}
+
 func capitalize(s string) string {
 	if s == "" {
 		return s
@@ -450,6 +586,118 @@ func errorResponseRef(description, schemaName string) *openapi3.ResponseRef {
 	}
 }
 
+// messageResponseRef creates an OpenAPI response with an inline message-only
+// JSON body, used for framework-generated errors without a component schema.
+func messageResponseRef(description string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{
+		Value: &openapi3.Response{
+			Description: new(description),
+			Content: map[string]*openapi3.MediaType{
+				"application/json": {
+					Schema: messageOnlySchemaRef(),
+				},
+			},
+		},
+	}
+}
+
+// emptyBodySchema returns the inline schema for a request body without body
+// fields: any object, or only the empty object on strict routes.
+func emptyBodySchema(strict bool) *openapi3.Schema {
+	schema := &openapi3.Schema{Type: &openapi3.Types{"object"}}
+	if strict {
+		closeObjectSchemas(schema)
+	}
+	return schema
+}
+
+// codecContent builds a content map listing every registered codec's media
+// type with the same schema.
+func (a *API) codecContent(schema *openapi3.SchemaRef) openapi3.Content {
+	content := make(openapi3.Content, len(a.codecs))
+	for _, c := range a.codecs {
+		content[c.mediaType] = &openapi3.MediaType{Schema: schema}
+	}
+	return content
+}
+
+// locationHeaderRef documents the Location header of a redirect.
+func locationHeaderRef() *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Name:        "Location",
+				In:          "header",
+				Description: "The URL to redirect to.",
+				Required:    true,
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type:   &openapi3.Types{"string"},
+						Format: "uri-reference",
+					},
+				},
+			},
+		},
+	}
+}
+
+// mergeHeaders returns the headers of a and b, with b taking precedence. It
+// returns nil if both are empty.
+func mergeHeaders(a, b openapi3.Headers) openapi3.Headers {
+	if len(b) == 0 {
+		return a
+	}
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = make(openapi3.Headers, len(b))
+	}
+	maps.Copy(merged, b)
+	return merged
+}
+
+// typedResponse documents a response whose body is t encoded with the API's
+// codecs. Header-tagged fields of t are stripped from the body schema. It
+// returns nil when there is neither a body nor headers to document.
+func (a *API) typedResponse(t reflect.Type, status int, hasRespHeader bool, headers openapi3.Headers) (*openapi3.Response, error) {
+	outSchema, err := a.generateSchemaRef(t)
+	if err != nil {
+		return nil, err
+	}
+	if outSchema == nil {
+		if len(headers) == 0 {
+			return nil, nil
+		}
+		return &openapi3.Response{
+			Description: new(http.StatusText(status)),
+			Headers:     headers,
+		}, nil
+	}
+	if hasRespHeader {
+		stripRespHeaderFields(t, outSchema.Value)
+	}
+	resp := &openapi3.Response{
+		Description: new(http.StatusText(status)),
+	}
+	if outSchema.Ref != "" && len(outSchema.Value.Properties) > 0 {
+		// Named object schema — reference by $ref.
+		resp.Content = a.codecContent(&openapi3.SchemaRef{
+			Ref: fmt.Sprintf("#/components/schemas/%s", outSchema.Ref),
+		})
+		a.spec.Components.Schemas[outSchema.Ref] = &openapi3.SchemaRef{
+			Value: outSchema.Value,
+		}
+	} else if outSchema.Value.Type != nil && !outSchema.Value.Type.Is("object") {
+		// Non-object schema (array, primitive) — inline directly.
+		// Register any nested object schemas (e.g. array items) in components.
+		a.registerNestedSchemas(outSchema)
+		resp.Content = a.codecContent(outSchema)
+	}
+	if len(headers) > 0 {
+		resp.Headers = headers
+	}
+	return resp, nil
+}
+
 func (a *API) generateSchemaRef(t reflect.Type) (*openapi3.SchemaRef, error) {
 	if t == nil {
 		return nil, nil
@@ -482,31 +730,113 @@ func (a *API) generateQueryParams(t reflect.Type) ([]*openapi3.ParameterRef, err
 			continue
 		}
 		name := queryFieldName(field)
-		schema := fieldToOpenAPISchema(field.Type)
+		required := hasRule(field.Tag.Get("validate"), "required")
+
+		if isDeepObjectType(field.Type, a.scalars) {
+			schema, err := a.deepObjectSchema(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %q: %w", name, err)
+			}
+			params = append(params, &openapi3.ParameterRef{
+				Value: &openapi3.Parameter{
+					Name:        name,
+					In:          "query",
+					Required:    required,
+					Deprecated:  isDeprecated(field.Tag),
+					Description: fieldDescription(field.Tag),
+					Style:       openapi3.SerializationDeepObject,
+					Explode:     new(true),
+					Schema:      schema,
+				},
+			})
+			a.recordParamDoc(params[len(params)-1].Value, t, field)
+			continue
+		}
+
+		schema := a.fieldToOpenAPISchema(field.Type)
 
 		// Apply validation constraints and enum lookup
 		if err := a.schemaCustomizer(name, field.Type, field.Tag, schema.Value); err != nil {
 			return nil, err
 		}
 
-		required := hasRule(field.Tag.Get("validate"), "required")
+		param := &openapi3.Parameter{
+			Name:        name,
+			In:          "query",
+			Required:    required,
+			Deprecated:  isDeprecated(field.Tag),
+			Description: fieldDescription(field.Tag),
+			Schema:      schema,
+		}
+		if hasQueryStyleOptions(field) {
+			if !a.scalars.isListType(field.Type) {
+				return nil, fmt.Errorf("query parameter %q: style options require a slice field, got %s", name, field.Type)
+			}
+			style, explode, err := queryArrayStyle(field)
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %q: %w", name, err)
+			}
+			param.Style = style
+			param.Explode = new(explode)
+		}
+		params = append(params, &openapi3.ParameterRef{Value: param})
+		a.recordParamDoc(param, t, field)
+	}
+	return params, nil
+}
 
-		params = append(params, &openapi3.ParameterRef{
-			Value: &openapi3.Parameter{
-				Name:     name,
-				In:       "query",
-				Required: required,
-				Schema:   schema,
+// deepObjectSchema builds the inline object schema for a nested struct or map
+// query parameter. Only one level of nesting is supported, matching the
+// OpenAPI deepObject serialization.
+func (a *API) deepObjectSchema(t reflect.Type) (*openapi3.SchemaRef, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map {
+		if isDeepObjectType(t.Elem(), a.scalars) {
+			return nil, fmt.Errorf("nested objects are not supported in deepObject parameters")
+		}
+		return &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: &openapi3.Types{"object"},
+				AdditionalProperties: openapi3.AdditionalProperties{
+					Schema: a.fieldToOpenAPISchema(t.Elem()),
+				},
 			},
-		})
+		}, nil
 	}
-	return params, nil
+
+	schema := &openapi3.Schema{
+		Type:       &openapi3.Types{"object"},
+		Properties: make(openapi3.Schemas),
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		if isDeepObjectType(f.Type, a.scalars) {
+			return nil, fmt.Errorf("nested objects are not supported in deepObject parameters (field %q)", f.Name)
+		}
+		key := deepObjectFieldName(f)
+		prop := a.fieldToOpenAPISchema(f.Type)
+		if err := a.schemaCustomizer(key, f.Type, f.Tag, prop.Value); err != nil {
+			return nil, err
+		}
+		schema.Properties[key] = prop
+		if hasRule(f.Tag.Get("validate"), "required") {
+			schema.Required = append(schema.Required, key)
+		}
+	}
+	return &openapi3.SchemaRef{Value: schema}, nil
 }
 
 // generateFormSchema builds an inline OpenAPI schema and encoding map for multipart/form-data.
 // Only fields with `form` tags are included; query-tagged fields are skipped.
-// The encoding map is populated for fields with `accept` tags.
-func (a *API) generateFormSchema(t reflect.Type) (*openapi3.Schema, map[string]*openapi3.Encoding) {
+// The encoding map is populated for fields with `accept` tags and for
+// JSON-encoded parts, whose schemas are generated like a request body. File
+// schemas document maxsize as maxLength, maxfiles as maxItems, and a single
+// accepted type as contentMediaType.
+func (a *API) generateFormSchema(t reflect.Type) (*openapi3.Schema, map[string]*openapi3.Encoding, error) {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -526,31 +856,41 @@ func (a *API) generateFormSchema(t reflect.Type) (*openapi3.Schema, map[string]*
 
 		var propSchema *openapi3.SchemaRef
 		switch field.Type {
-		case fileHeaderType:
-			// Single file upload
+		case fileHeaderType, filePartReaderType:
+			// Single file upload, buffered or streamed
 			propSchema = &openapi3.SchemaRef{
-				Value: &openapi3.Schema{
-					Type:   &openapi3.Types{"string"},
-					Format: "binary",
-				},
+				Value: fileSchema(field),
 			}
 		case fileHeaderSliceType:
 			// Multiple file upload
-			propSchema = &openapi3.SchemaRef{
-				Value: &openapi3.Schema{
-					Type: &openapi3.Types{"array"},
-					Items: &openapi3.SchemaRef{
-						Value: &openapi3.Schema{
-							Type:   &openapi3.Types{"string"},
-							Format: "binary",
-						},
-					},
-				},
+			arr := &openapi3.Schema{
+				Type:  &openapi3.Types{"array"},
+				Items: &openapi3.SchemaRef{Value: fileSchema(field)},
 			}
+			if limits, _ := parseFileLimits(field); limits.maxFiles > 0 {
+				arr.MaxItems = new(uint64(limits.maxFiles))
+			}
+			propSchema = &openapi3.SchemaRef{Value: arr}
 		default:
+			if isJSONFormField(field, a.scalars) {
+				// JSON-encoded part, documented like a request body.
+				ref, err := a.generateSchemaRef(field.Type)
+				if err != nil {
+					return nil, nil, err
+				}
+				a.registerNestedSchemas(ref)
+				propSchema = ref
+				if encoding == nil {
+					encoding = make(map[string]*openapi3.Encoding)
+				}
+				encoding[name] = &openapi3.Encoding{ContentType: "application/json"}
+				break
+			}
 			// Text form field
-			propSchema = fieldToOpenAPISchema(field.Type)
-			_ = a.schemaCustomizer(name, field.Type, field.Tag, propSchema.Value)
+			propSchema = a.fieldToOpenAPISchema(field.Type)
+			if err := a.schemaCustomizer(name, field.Type, field.Tag, propSchema.Value); err != nil {
+				return nil, nil, err
+			}
 		}
 
 		schema.Properties[name] = propSchema
@@ -570,18 +910,34 @@ func (a *API) generateFormSchema(t reflect.Type) (*openapi3.Schema, map[string]*
 		}
 	}
 
-	return schema, encoding
+	return schema, encoding, nil
+}
+
+// fileSchema returns the binary string schema for a single file of a form
+// file field, with its maxsize and accept constraints.
+func fileSchema(field reflect.StructField) *openapi3.Schema {
+	schema := &openapi3.Schema{
+		Type:   &openapi3.Types{"string"},
+		Format: "binary",
+	}
+	if limits, _ := parseFileLimits(field); limits.maxSize > 0 {
+		schema.MaxLength = new(uint64(limits.maxSize))
+	}
+	if accept := acceptTypes(field); len(accept) == 1 {
+		schema.Extensions = map[string]any{"contentMediaType": accept[0]}
+	}
+	return schema
 }
 
 // fieldToOpenAPISchema maps a Go type to an OpenAPI schema.
-func fieldToOpenAPISchema(t reflect.Type) *openapi3.SchemaRef {
+func (a *API) fieldToOpenAPISchema(t reflect.Type) *openapi3.SchemaRef {
 	// Unwrap pointer
 	if t.Kind() == reflect.Pointer {
-		return fieldToOpenAPISchema(t.Elem())
+		return a.fieldToOpenAPISchema(t.Elem())
 	}
 
-	// Handle slices
-	if t.Kind() == reflect.Slice {
+	// Handle slices, except text scalars such as net.IP
+	if a.scalars.isListType(t) {
 		items := scalarToOpenAPISchema(t.Elem())
 		return &openapi3.SchemaRef{
 			Value: &openapi3.Schema{
@@ -671,12 +1027,15 @@ func (a *API) generateHeaderParams(t reflect.Type) ([]*openapi3.ParameterRef, er
 
 		params = append(params, &openapi3.ParameterRef{
 			Value: &openapi3.Parameter{
-				Name:     name,
-				In:       "header",
-				Required: required,
-				Schema:   schema,
+				Name:        name,
+				In:          "header",
+				Required:    required,
+				Deprecated:  isDeprecated(field.Tag),
+				Description: fieldDescription(field.Tag),
+				Schema:      schema,
 			},
 		})
+		a.recordParamDoc(params[len(params)-1].Value, t, field)
 	}
 	return params, nil
 }
@@ -708,6 +1067,75 @@ func stripHeaderFields(t reflect.Type, schema *openapi3.Schema) {
 	}
 }
 
+// generateCookieParams produces OpenAPI parameter definitions for a cookie struct type.
+// Only fields with `cookie` tags are included. Slices are not supported for cookies.
+func (a *API) generateCookieParams(t reflect.Type) ([]*openapi3.ParameterRef, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cookie type must be a struct, got %s", t.Kind())
+	}
+
+	var params []*openapi3.ParameterRef
+	for field := range t.Fields() {
+		if !field.IsExported() {
+			continue
+		}
+		if !hasCookieTag(field) {
+			continue
+		}
+		name := cookieFieldName(field)
+		schema := scalarToOpenAPISchema(field.Type)
+
+		// Apply validation constraints and enum lookup
+		if err := a.schemaCustomizer(name, field.Type, field.Tag, schema.Value); err != nil {
+			return nil, err
+		}
+
+		required := hasRule(field.Tag.Get("validate"), "required")
+
+		params = append(params, &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name:        name,
+				In:          "cookie",
+				Required:    required,
+				Deprecated:  isDeprecated(field.Tag),
+				Description: fieldDescription(field.Tag),
+				Schema:      schema,
+			},
+		})
+		a.recordParamDoc(params[len(params)-1].Value, t, field)
+	}
+	return params, nil
+}
+
+// stripCookieFields removes cookie-tagged fields from a body schema's Properties and Required.
+func stripCookieFields(t reflect.Type, schema *openapi3.Schema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || schema == nil {
+		return
+	}
+	for f := range t.Fields() {
+		if !f.IsExported() || !hasCookieTag(f) {
+			continue
+		}
+		jname := jsonFieldName(f)
+		if jname == "" || jname == "-" {
+			continue
+		}
+		delete(schema.Properties, jname)
+		for j, req := range schema.Required {
+			if req == jname {
+				schema.Required = append(schema.Required[:j], schema.Required[j+1:]...)
+				break
+			}
+		}
+	}
+}
+
 // stripPathFields removes path-tagged fields from a body schema's Properties and Required.
 func stripPathFields(t reflect.Type, schema *openapi3.Schema) {
 	for t.Kind() == reflect.Pointer {
@@ -0,0 +1,479 @@
+package shiftapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	spec "github.com/swaggest/go-asyncapi/spec-2.4.0"
+)
+
+// ErrUnauthorized is returned by a [Verifier] to reject missing or invalid
+// credentials. It is sent as 401 Unauthorized, with a WWW-Authenticate
+// challenge for HTTP basic and bearer schemes.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden is returned by a [Verifier] to reject valid credentials that
+// lack the scopes or permissions the route requires. It is sent as 403
+// Forbidden.
+var ErrForbidden = errors.New("forbidden")
+
+// errNoCredentials is returned by a scheme when the request does not carry
+// its credentials at all, so that another accepted scheme can be tried.
+var errNoCredentials = errors.New("no credentials")
+
+// Credentials are the credentials a request presented for a
+// [SecurityScheme], passed to its [Verifier].
+type Credentials struct {
+	Token    string   // API key, or bearer or OAuth2 access token
+	Username string   // HTTP basic username
+	Password string   // HTTP basic password
+	Scopes   []string // scopes the route requires, from WithSecurity
+}
+
+// Verifier checks the credentials of a request and returns the principal
+// they identify, such as a user or service account. Return [ErrUnauthorized]
+// for invalid credentials and [ErrForbidden] when the principal lacks the
+// required scopes. Other errors are handled like handler errors, so types
+// declared with [WithError] are honored.
+type Verifier[P any] func(r *http.Request, creds Credentials) (P, error)
+
+// SecurityScheme describes how clients authenticate and how the server
+// verifies their credentials. Create one with [APIKeyScheme], [BearerScheme],
+// [BasicScheme], or [OAuth2Scheme] and require it with [WithSecurity].
+//
+// The scheme is documented under components.securitySchemes in the OpenAPI
+// spec. On each request to a route that requires it, the scheme's [Verifier]
+// runs before the handler and the principal it returns is stored in the
+// request context under [SecurityScheme.Key]:
+//
+//	var bearer = shiftapi.BearerScheme("bearerAuth", "JWT", verifyToken)
+//
+//	func getProfile(r *http.Request, _ struct{}) (*Profile, error) {
+//	    user, _ := shiftapi.FromContext(r, bearer.Key())
+//	    return profileOf(user), nil
+//	}
+type SecurityScheme[P any] struct {
+	name      string
+	spec      *openapi3.SecurityScheme
+	challenge string // WWW-Authenticate value, empty if none
+	extract   func(r *http.Request) (Credentials, bool)
+	verify    Verifier[P]
+	key       *ContextKey[P]
+}
+
+// Key returns the context key under which the verified principal is stored.
+// Use it with [FromContext].
+func (s *SecurityScheme[P]) Key() *ContextKey[P] {
+	return s.key
+}
+
+func newSecurityScheme[P any](name string, spec *openapi3.SecurityScheme, extract func(*http.Request) (Credentials, bool), verify Verifier[P]) *SecurityScheme[P] {
+	if name == "" {
+		panic("shiftapi: security scheme name must not be empty")
+	}
+	if verify == nil {
+		panic(fmt.Sprintf("shiftapi: security scheme %q requires a verifier", name))
+	}
+	return &SecurityScheme[P]{
+		name:    name,
+		spec:    spec,
+		extract: extract,
+		verify:  verify,
+		key:     NewContextKey[P](name),
+	}
+}
+
+// APIKeyScheme creates a scheme that reads an API key from the header, query
+// parameter, or cookie named param. The in argument is "header", "query", or
+// "cookie".
+//
+//	apiKey := shiftapi.APIKeyScheme("apiKey", "header", "X-API-Key", verifyKey)
+func APIKeyScheme[P any](name, in, param string, verify Verifier[P]) *SecurityScheme[P] {
+	var extract func(r *http.Request) (string, bool)
+	switch in {
+	case "header":
+		extract = func(r *http.Request) (string, bool) {
+			v := r.Header.Get(param)
+			return v, v != ""
+		}
+	case "query":
+		extract = func(r *http.Request) (string, bool) {
+			v := r.URL.Query().Get(param)
+			return v, v != ""
+		}
+	case "cookie":
+		extract = func(r *http.Request) (string, bool) {
+			c, err := r.Cookie(param)
+			if err != nil || c.Value == "" {
+				return "", false
+			}
+			return c.Value, true
+		}
+	default:
+		panic(fmt.Sprintf("shiftapi: API key scheme %q must be in \"header\", \"query\", or \"cookie\", got %q", name, in))
+	}
+	spec := &openapi3.SecurityScheme{Type: "apiKey", In: in, Name: param}
+	return newSecurityScheme(name, spec, func(r *http.Request) (Credentials, bool) {
+		key, ok := extract(r)
+		return Credentials{Token: key}, ok
+	}, verify)
+}
+
+// BearerScheme creates a scheme that reads a token from an
+// "Authorization: Bearer" header. The optional format, such as "JWT", is a
+// documentation hint for clients.
+func BearerScheme[P any](name, format string, verify Verifier[P]) *SecurityScheme[P] {
+	spec := &openapi3.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: format}
+	s := newSecurityScheme(name, spec, bearerCredentials, verify)
+	s.challenge = "Bearer"
+	return s
+}
+
+// BasicScheme creates a scheme that reads a username and password from an
+// "Authorization: Basic" header.
+func BasicScheme[P any](name string, verify Verifier[P]) *SecurityScheme[P] {
+	spec := &openapi3.SecurityScheme{Type: "http", Scheme: "basic"}
+	s := newSecurityScheme(name, spec, func(r *http.Request) (Credentials, bool) {
+		user, pass, ok := r.BasicAuth()
+		return Credentials{Username: user, Password: pass}, ok
+	}, verify)
+	s.challenge = fmt.Sprintf("Basic realm=%q", name)
+	return s
+}
+
+// OAuthFlows describes the OAuth2 flows an [OAuth2Scheme] supports.
+type OAuthFlows struct {
+	Implicit          *OAuthFlow
+	Password          *OAuthFlow
+	ClientCredentials *OAuthFlow
+	AuthorizationCode *OAuthFlow
+}
+
+// OAuthFlow describes the endpoints and scopes of an OAuth2 flow. Scopes maps
+// each scope name to a short description.
+type OAuthFlow struct {
+	AuthorizationURL string
+	TokenURL         string
+	RefreshURL       string
+	Scopes           map[string]string
+}
+
+// OAuth2Scheme creates a scheme that reads an OAuth2 access token from an
+// "Authorization: Bearer" header. The scopes passed to [WithSecurity] are
+// given to the verifier in [Credentials.Scopes] for it to check against the
+// token.
+//
+//	oauth := shiftapi.OAuth2Scheme("oauth2", shiftapi.OAuthFlows{
+//	    AuthorizationCode: &shiftapi.OAuthFlow{
+//	        AuthorizationURL: "https://auth.example.com/authorize",
+//	        TokenURL:         "https://auth.example.com/token",
+//	        Scopes:           map[string]string{"read:items": "Read items"},
+//	    },
+//	}, verifyAccessToken)
+func OAuth2Scheme[P any](name string, flows OAuthFlows, verify Verifier[P]) *SecurityScheme[P] {
+	spec := &openapi3.SecurityScheme{
+		Type: "oauth2",
+		Flows: &openapi3.OAuthFlows{
+			Implicit:          flows.Implicit.toOpenAPI(),
+			Password:          flows.Password.toOpenAPI(),
+			ClientCredentials: flows.ClientCredentials.toOpenAPI(),
+			AuthorizationCode: flows.AuthorizationCode.toOpenAPI(),
+		},
+	}
+	s := newSecurityScheme(name, spec, bearerCredentials, verify)
+	s.challenge = "Bearer"
+	return s
+}
+
+func (f *OAuthFlow) toOpenAPI() *openapi3.OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	scopes := f.Scopes
+	if scopes == nil {
+		scopes = map[string]string{}
+	}
+	return &openapi3.OAuthFlow{
+		AuthorizationURL: f.AuthorizationURL,
+		TokenURL:         f.TokenURL,
+		RefreshURL:       f.RefreshURL,
+		Scopes:           scopes,
+	}
+}
+
+// bearerCredentials reads the token of an "Authorization: Bearer" header.
+func bearerCredentials(r *http.Request) (Credentials, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Credentials{}, false
+	}
+	token = strings.TrimSpace(token)
+	return Credentials{Token: token}, token != ""
+}
+
+// securityScheme is the type-erased view of a [SecurityScheme] used at
+// registration and request time.
+type securityScheme interface {
+	schemeName() string
+	openAPIScheme() *openapi3.SecurityScheme
+	wwwAuthenticate() string
+	authenticate(r *http.Request, scopes []string) (*http.Request, error)
+}
+
+func (s *SecurityScheme[P]) schemeName() string                      { return s.name }
+func (s *SecurityScheme[P]) openAPIScheme() *openapi3.SecurityScheme { return s.spec }
+func (s *SecurityScheme[P]) wwwAuthenticate() string                 { return s.challenge }
+
+// authenticate verifies the request's credentials and returns the request
+// with the principal stored in its context. It returns errNoCredentials if
+// the request carries none for this scheme.
+func (s *SecurityScheme[P]) authenticate(r *http.Request, scopes []string) (*http.Request, error) {
+	creds, ok := s.extract(r)
+	if !ok {
+		return nil, errNoCredentials
+	}
+	creds.Scopes = scopes
+	p, err := s.verify(r, creds)
+	if err != nil {
+		return nil, err
+	}
+	return SetContext(r, s.key, p), nil
+}
+
+// securityRequirement is one accepted way to authenticate: a scheme and the
+// scopes it must grant. A nil scheme allows anonymous requests.
+type securityRequirement struct {
+	scheme securityScheme
+	scopes []string
+}
+
+// securityConfig is the set of alternative requirements a route accepts; a
+// request is authenticated if it satisfies any one of them.
+type securityConfig struct {
+	requirements []securityRequirement
+}
+
+// with returns the config with req appended, allocating it if nil.
+func (c *securityConfig) with(req securityRequirement) *securityConfig {
+	if c == nil {
+		c = &securityConfig{}
+	}
+	c.requirements = append(c.requirements, req)
+	return c
+}
+
+// WithSecurity requires requests to authenticate with the given scheme,
+// granting the given scopes. The requirement is documented on each affected
+// operation, along with 401 and, when scopes are required, 403 responses.
+// WebSocket routes check it on the handshake and document it in the AsyncAPI
+// spec.
+// Repeat WithSecurity to accept any one of several schemes.
+//
+// WithSecurity returns an [Option] that works at any level. The innermost
+// level that declares security replaces the requirements of outer levels, so
+// a route can require different scopes than its group:
+//
+//	api := shiftapi.New(shiftapi.WithSecurity(bearer))
+//	shiftapi.Handle(api, "DELETE /items/{id}", deleteItem,
+//	    shiftapi.WithSecurity(oauth, "write:items"),
+//	)
+//	shiftapi.Handle(api, "GET /health", health, shiftapi.WithoutSecurity())
+func WithSecurity[P any](scheme *SecurityScheme[P], scopes ...string) Option {
+	return func(c sharedConfig) {
+		c.addSecurity(securityRequirement{scheme: scheme, scopes: scopes})
+	}
+}
+
+// WithoutSecurity allows anonymous requests, overriding requirements declared
+// at outer levels. Combined with [WithSecurity] at the same level, it makes
+// authentication optional: requests without credentials are let through,
+// while presented credentials are still verified.
+func WithoutSecurity() Option {
+	return func(c sharedConfig) {
+		c.addSecurity(securityRequirement{})
+	}
+}
+
+// requireSecurity wraps h so that it runs only for requests that satisfy one
+// of the route's security requirements. Schemes whose credentials are absent
+// are skipped; the first verification failure, or ErrUnauthorized if no
+// credentials were presented, is sent as the error response.
+func requireSecurity(h http.Handler, sec *securityConfig, hc *handlerConfig) http.Handler {
+	var challenges []string
+	for _, req := range sec.requirements {
+		if req.scheme == nil {
+			continue
+		}
+		if c := req.scheme.wwwAuthenticate(); c != "" && !slices.Contains(challenges, c) {
+			challenges = append(challenges, c)
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anonymous := false
+		var authErr error
+		for _, req := range sec.requirements {
+			if req.scheme == nil {
+				anonymous = true
+				continue
+			}
+			ar, err := req.scheme.authenticate(r, req.scopes)
+			if err == nil {
+				h.ServeHTTP(w, ar)
+				return
+			}
+			if authErr == nil && !errors.Is(err, errNoCredentials) {
+				authErr = err
+			}
+		}
+		if authErr == nil {
+			if anonymous {
+				h.ServeHTTP(w, r)
+				return
+			}
+			authErr = ErrUnauthorized
+		}
+		for _, sh := range hc.staticHeaders {
+			w.Header().Set(sh.name, sh.value)
+		}
+		handleError(&challengeWriter{ResponseWriter: w, challenges: challenges}, hc, authErr)
+	})
+}
+
+// challengeWriter adds the route's WWW-Authenticate challenges when the
+// error response it carries is a 401.
+type challengeWriter struct {
+	http.ResponseWriter
+	challenges []string
+}
+
+func (w *challengeWriter) WriteHeader(status int) {
+	if status == http.StatusUnauthorized {
+		for _, c := range w.challenges {
+			w.Header().Add("WWW-Authenticate", c)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// addSecurityRequirements documents the requirements under the operation's security
+// and their schemes under components.securitySchemes. It reports whether any
+// requirement authenticates and whether any requires scopes.
+func (a *API) addSecurityRequirements(op *openapi3.Operation, sec *securityConfig) (authenticated, scoped bool) {
+	reqs := openapi3.SecurityRequirements{}
+	anonymous := false
+	for _, req := range sec.requirements {
+		if req.scheme == nil {
+			anonymous = true
+			continue
+		}
+		name := a.claimSecurityScheme(req.scheme)
+		if a.spec.Components.SecuritySchemes == nil {
+			a.spec.Components.SecuritySchemes = make(openapi3.SecuritySchemes)
+		}
+		a.spec.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: req.scheme.openAPIScheme()}
+
+		scopes := req.scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		reqs = append(reqs, openapi3.SecurityRequirement{name: scopes})
+		authenticated = true
+		scoped = scoped || len(req.scopes) > 0
+	}
+	// An empty requirement marks authentication as optional; on its own the
+	// operation is public, documented as an empty security list.
+	if anonymous && authenticated {
+		reqs = append(reqs, openapi3.SecurityRequirement{})
+	}
+	op.Security = &reqs
+	return authenticated, scoped
+}
+
+// claimSecurityScheme records the scheme under its name and returns the
+// name, panicking if another scheme already uses it.
+func (a *API) claimSecurityScheme(scheme securityScheme) string {
+	name := scheme.schemeName()
+	if existing, ok := a.securitySchemes[name]; ok && existing != scheme {
+		panic(fmt.Sprintf("shiftapi: security scheme name %q is used by two different schemes", name))
+	}
+	if a.securitySchemes == nil {
+		a.securitySchemes = make(map[string]securityScheme)
+	}
+	a.securitySchemes[name] = scheme
+	return name
+}
+
+// addAsyncSecurityRequirements documents the requirements of a WebSocket
+// route under components.securitySchemes of the AsyncAPI spec and returns
+// them as an operation's security list, nil if the route is public.
+func (a *API) addAsyncSecurityRequirements(sec *securityConfig) []map[string][]string {
+	if sec == nil {
+		return nil
+	}
+	var reqs []map[string][]string
+	anonymous := false
+	for _, req := range sec.requirements {
+		if req.scheme == nil {
+			anonymous = true
+			continue
+		}
+		name := a.claimSecurityScheme(req.scheme)
+		a.asyncSpec.ComponentsEns().SecuritySchemesEns().WithMapOfComponentsSecuritySchemesWDValuesItem(name,
+			spec.ComponentsSecuritySchemesWD{SecurityScheme: asyncAPISecurityScheme(req.scheme.openAPIScheme())})
+
+		scopes := req.scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		reqs = append(reqs, map[string][]string{name: scopes})
+	}
+	if anonymous && len(reqs) > 0 {
+		reqs = append(reqs, map[string][]string{})
+	}
+	return reqs
+}
+
+// asyncAPISecurityScheme converts an OpenAPI security scheme to its AsyncAPI
+// 2.4 counterpart. API keys map to httpApiKey, since WebSocket handshakes are
+// HTTP requests.
+func asyncAPISecurityScheme(s *openapi3.SecurityScheme) *spec.SecurityScheme {
+	switch s.Type {
+	case "apiKey":
+		return &spec.SecurityScheme{HTTPSecurityScheme: &spec.HTTPSecurityScheme{
+			APIKeyHTTPSecurityScheme: &spec.APIKeyHTTPSecurityScheme{Name: s.Name, In: spec.APIKeyHTTPSecuritySchemeIn(s.In)},
+		}}
+	case "http":
+		if strings.EqualFold(s.Scheme, "bearer") {
+			return &spec.SecurityScheme{HTTPSecurityScheme: &spec.HTTPSecurityScheme{
+				BearerHTTPSecurityScheme: &spec.BearerHTTPSecurityScheme{BearerFormat: s.BearerFormat},
+			}}
+		}
+		return &spec.SecurityScheme{HTTPSecurityScheme: &spec.HTTPSecurityScheme{
+			NonBearerHTTPSecurityScheme: &spec.NonBearerHTTPSecurityScheme{Scheme: s.Scheme},
+		}}
+	case "oauth2":
+		return &spec.SecurityScheme{Oauth2Flows: &spec.Oauth2Flows{Flows: spec.Oauth2FlowsFlows{
+			Implicit:          asyncAPIOAuthFlow(s.Flows.Implicit),
+			Password:          asyncAPIOAuthFlow(s.Flows.Password),
+			ClientCredentials: asyncAPIOAuthFlow(s.Flows.ClientCredentials),
+			AuthorizationCode: asyncAPIOAuthFlow(s.Flows.AuthorizationCode),
+		}}}
+	}
+	panic(fmt.Sprintf("shiftapi: unsupported security scheme type %q", s.Type))
+}
+
+func asyncAPIOAuthFlow(f *openapi3.OAuthFlow) *spec.Oauth2Flow {
+	if f == nil {
+		return nil
+	}
+	return &spec.Oauth2Flow{
+		AuthorizationURL: f.AuthorizationURL,
+		TokenURL:         f.TokenURL,
+		RefreshURL:       f.RefreshURL,
+		Scopes:           f.Scopes,
+	}
+}
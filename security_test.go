@@ -0,0 +1,328 @@
+package shiftapi_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/fcjr/shiftapi"
+)
+
+type Principal struct {
+	Name   string
+	Scopes []string
+}
+
+var tokens = map[string]Principal{
+	"alice-token": {Name: "alice", Scopes: []string{"read:items", "write:items"}},
+	"bob-token":   {Name: "bob", Scopes: []string{"read:items"}},
+}
+
+func verifyToken(r *http.Request, creds shiftapi.Credentials) (Principal, error) {
+	p, ok := tokens[creds.Token]
+	if !ok {
+		return Principal{}, shiftapi.ErrUnauthorized
+	}
+	for _, s := range creds.Scopes {
+		if !slices.Contains(p.Scopes, s) {
+			return Principal{}, shiftapi.ErrForbidden
+		}
+	}
+	return p, nil
+}
+
+var (
+	bearerAuth = shiftapi.BearerScheme("bearerAuth", "JWT", verifyToken)
+	apiKeyAuth = shiftapi.APIKeyScheme("apiKey", "header", "X-API-Key", verifyToken)
+	basicAuth  = shiftapi.BasicScheme("basicAuth", func(r *http.Request, creds shiftapi.Credentials) (Principal, error) {
+		if creds.Username != "admin" || creds.Password != "secret" {
+			return Principal{}, shiftapi.ErrUnauthorized
+		}
+		return Principal{Name: creds.Username}, nil
+	})
+	oauthAuth = shiftapi.OAuth2Scheme("oauth2", shiftapi.OAuthFlows{
+		ClientCredentials: &shiftapi.OAuthFlow{
+			TokenURL: "https://auth.example.com/token",
+			Scopes:   map[string]string{"read:items": "Read items", "write:items": "Write items"},
+		},
+	}, verifyToken)
+)
+
+func whoami(key *shiftapi.ContextKey[Principal]) shiftapi.HandlerFunc[struct{}, map[string]string] {
+	return func(r *http.Request, _ struct{}) (map[string]string, error) {
+		p, ok := shiftapi.FromContext(r, key)
+		if !ok {
+			return map[string]string{"name": "anonymous"}, nil
+		}
+		return map[string]string{"name": p.Name}, nil
+	}
+}
+
+func TestSecurity_bearer(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /me", whoami(bearerAuth.Key()), shiftapi.WithSecurity(bearerAuth))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Authorization": "Bearer alice-token"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := decodeJSON[map[string]string](t, resp); got["name"] != "alice" {
+		t.Errorf("expected principal in context, got %v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/me", "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("WWW-Authenticate"); got != "Bearer" {
+		t.Errorf("expected Bearer challenge, got %q", got)
+	}
+	if got := decodeJSON[map[string]string](t, resp); got["message"] != "unauthorized" {
+		t.Errorf("unexpected body %v", got)
+	}
+
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/me", "", map[string]string{"Authorization": "Bearer nope"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unknown token, got %d", resp.StatusCode)
+	}
+}
+
+func TestSecurity_scopes(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "DELETE /items/{id}", whoami(oauthAuth.Key()), shiftapi.WithSecurity(oauthAuth, "write:items"))
+
+	resp := doRequestWithHeaders(t, api, http.MethodDelete, "/items/1", "", map[string]string{"Authorization": "Bearer bob-token"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("WWW-Authenticate"); got != "" {
+		t.Errorf("expected no challenge on 403, got %q", got)
+	}
+	resp = doRequestWithHeaders(t, api, http.MethodDelete, "/items/1", "", map[string]string{"Authorization": "Bearer alice-token"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestSecurity_apiKeyAndBasic(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /key", whoami(apiKeyAuth.Key()), shiftapi.WithSecurity(apiKeyAuth))
+	shiftapi.Handle(api, "GET /basic", whoami(basicAuth.Key()), shiftapi.WithSecurity(basicAuth))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/key", "", map[string]string{"X-API-Key": "bob-token"})
+	if got := decodeJSON[map[string]string](t, resp); got["name"] != "bob" {
+		t.Errorf("unexpected body %v", got)
+	}
+
+	creds := base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/basic", "", map[string]string{"Authorization": "Basic " + creds})
+	if got := decodeJSON[map[string]string](t, resp); got["name"] != "admin" {
+		t.Errorf("unexpected body %v", got)
+	}
+	resp = doRequest(t, api, http.MethodGet, "/basic", "")
+	if got := resp.Header.Get("WWW-Authenticate"); got != `Basic realm="basicAuth"` {
+		t.Errorf("unexpected challenge %q", got)
+	}
+}
+
+func TestSecurity_alternativesAndOverrides(t *testing.T) {
+	api := shiftapi.New(shiftapi.WithSecurity(bearerAuth), shiftapi.WithSecurity(apiKeyAuth))
+	shiftapi.Handle(api, "GET /either", whoami(apiKeyAuth.Key()))
+	shiftapi.Handle(api, "GET /health", whoami(bearerAuth.Key()), shiftapi.WithoutSecurity())
+	shiftapi.Handle(api, "GET /optional", whoami(bearerAuth.Key()),
+		shiftapi.WithSecurity(bearerAuth), shiftapi.WithoutSecurity())
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/either", "", map[string]string{"X-API-Key": "alice-token"})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected API key to satisfy the second alternative, got %d", resp.StatusCode)
+	}
+	resp = doRequest(t, api, http.MethodGet, "/either", "")
+	if got := resp.Header.Values("WWW-Authenticate"); !slices.Equal(got, []string{"Bearer"}) {
+		t.Errorf("unexpected challenges %v", got)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/health", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected public route, got %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, api, http.MethodGet, "/optional", "")
+	if got := decodeJSON[map[string]string](t, resp); got["name"] != "anonymous" {
+		t.Errorf("expected anonymous access, got %v", got)
+	}
+	resp = doRequestWithHeaders(t, api, http.MethodGet, "/optional", "", map[string]string{"Authorization": "Bearer nope"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected presented credentials to be verified, got %d", resp.StatusCode)
+	}
+}
+
+func TestSecurity_withErrorType(t *testing.T) {
+	api := newTestAPI(t)
+	scheme := shiftapi.BearerScheme("bearerAuth", "", func(r *http.Request, creds shiftapi.Credentials) (Principal, error) {
+		return Principal{}, &AuthError{Message: "token expired", Realm: "api"}
+	})
+	g := api.Group("/v1",
+		shiftapi.WithSecurity(scheme),
+		shiftapi.WithError[*AuthError](http.StatusUnauthorized),
+	)
+	shiftapi.Handle(g, "GET /me", whoami(scheme.Key()))
+
+	resp := doRequestWithHeaders(t, api, http.MethodGet, "/v1/me", "", map[string]string{"Authorization": "Bearer x"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	if got := decodeJSON[map[string]string](t, resp); got["message"] != "token expired" {
+		t.Errorf("unexpected body %v", got)
+	}
+
+	op := api.Spec().Paths.Find("/v1/me").Get
+	if ref := op.Responses.Value("401").Value.Content.Get("application/json").Schema.Ref; ref != "#/components/schemas/AuthError" {
+		t.Errorf("expected 401 documented with AuthError, got %q", ref)
+	}
+}
+
+func TestSecurity_spec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /items", whoami(oauthAuth.Key()),
+		shiftapi.WithSecurity(oauthAuth, "read:items"),
+		shiftapi.WithSecurity(apiKeyAuth),
+	)
+	shiftapi.Handle(api, "GET /me", whoami(bearerAuth.Key()), shiftapi.WithSecurity(bearerAuth))
+	shiftapi.Handle(api, "GET /public", whoami(bearerAuth.Key()), shiftapi.WithoutSecurity())
+	spec := api.Spec()
+
+	schemes := spec.Components.SecuritySchemes
+	if s := schemes["oauth2"]; s == nil || s.Value.Type != "oauth2" || s.Value.Flows.ClientCredentials.TokenURL != "https://auth.example.com/token" {
+		t.Errorf("unexpected oauth2 scheme %+v", s)
+	}
+	if s := schemes["apiKey"]; s == nil || s.Value.In != "header" || s.Value.Name != "X-API-Key" {
+		t.Errorf("unexpected apiKey scheme %+v", s)
+	}
+	if s := schemes["bearerAuth"]; s == nil || s.Value.Scheme != "bearer" || s.Value.BearerFormat != "JWT" {
+		t.Errorf("unexpected bearer scheme %+v", s)
+	}
+
+	items := spec.Paths.Find("/items").Get
+	if items.Security == nil || len(*items.Security) != 2 {
+		t.Fatalf("expected two alternatives, got %v", items.Security)
+	}
+	if got := (*items.Security)[0]["oauth2"]; !slices.Equal(got, []string{"read:items"}) {
+		t.Errorf("unexpected oauth2 scopes %v", got)
+	}
+	if items.Responses.Value("401") == nil || items.Responses.Value("403") == nil {
+		t.Error("expected 401 and 403 on a scoped operation")
+	}
+
+	me := spec.Paths.Find("/me").Get
+	if me.Responses.Value("401") == nil || me.Responses.Value("403") != nil {
+		t.Error("expected 401 but not 403 without scopes")
+	}
+
+	public := spec.Paths.Find("/public").Get
+	if public.Security == nil || len(*public.Security) != 0 || public.Responses.Value("401") != nil {
+		t.Errorf("expected an empty security list, got %v", public.Security)
+	}
+}
+
+func TestSecurity_duplicateSchemeNamePanics(t *testing.T) {
+	api := newTestAPI(t)
+	other := shiftapi.BearerScheme("bearerAuth", "", verifyToken)
+	shiftapi.Handle(api, "GET /a", whoami(bearerAuth.Key()), shiftapi.WithSecurity(bearerAuth))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	shiftapi.Handle(api, "GET /b", whoami(other.Key()), shiftapi.WithSecurity(other))
+}
+
+func TestSecurity_wsSpec(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.HandleWS(api, "GET /ws",
+		shiftapi.Websocket(
+			noSetup,
+			shiftapi.WSSends(shiftapi.WSMessageType[wsServerMsg]("server")),
+			shiftapi.WSOn("echo", func(sender *shiftapi.WSSender, _ struct{}, msg wsClientMsg) error {
+				return sender.Send(wsServerMsg(msg))
+			}),
+		),
+		shiftapi.WithSecurity(oauthAuth, "read:items"),
+		shiftapi.WithSecurity(apiKeyAuth),
+	)
+
+	resp := doRequest(t, api, http.MethodGet, "/ws", "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the handshake to be rejected with 401, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Values("WWW-Authenticate"); !slices.Equal(got, []string{"Bearer"}) {
+		t.Errorf("unexpected challenges %v", got)
+	}
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi.json", nil))
+	var doc struct {
+		Components struct {
+			SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+		Channels map[string]map[string]struct {
+			Security []map[string][]string `json:"security"`
+		} `json:"channels"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+
+	schemes := doc.Components.SecuritySchemes
+	if s := schemes["apiKey"]; s["type"] != "httpApiKey" || s["in"] != "header" || s["name"] != "X-API-Key" {
+		t.Errorf("unexpected apiKey scheme %v", s)
+	}
+	if s := schemes["oauth2"]; s["type"] != "oauth2" || s["flows"] == nil {
+		t.Errorf("unexpected oauth2 scheme %v", s)
+	}
+	for _, op := range []string{"subscribe", "publish"} {
+		sec := doc.Channels["/ws"][op].Security
+		if len(sec) != 2 || !slices.Equal(sec[0]["oauth2"], []string{"read:items"}) || sec[1]["apiKey"] == nil {
+			t.Errorf("unexpected %s security %v", op, sec)
+		}
+	}
+}
+
+func TestSecurity_wsSpecBearerAndBasic(t *testing.T) {
+	api := newTestAPI(t)
+	for path, scheme := range map[string]shiftapi.Option{
+		"GET /bearer": shiftapi.WithSecurity(bearerAuth),
+		"GET /basic":  shiftapi.WithSecurity(basicAuth),
+	} {
+		shiftapi.HandleWS(api, path,
+			shiftapi.Websocket(
+				noSetup,
+				shiftapi.WSSends(shiftapi.WSMessageType[wsServerMsg]("server")),
+				shiftapi.WSOn("echo", func(sender *shiftapi.WSSender, _ struct{}, msg wsClientMsg) error {
+					return sender.Send(wsServerMsg(msg))
+				}),
+			),
+			scheme,
+		)
+	}
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi.json", nil))
+	var doc struct {
+		Components struct {
+			SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if s := doc.Components.SecuritySchemes["bearerAuth"]; s["type"] != "http" || s["scheme"] != "bearer" || s["bearerFormat"] != "JWT" {
+		t.Errorf("unexpected bearer scheme %v", s)
+	}
+	if s := doc.Components.SecuritySchemes["basicAuth"]; s["type"] != "http" || s["scheme"] != "basic" {
+		t.Errorf("unexpected basic scheme %v", s)
+	}
+}
@@ -25,6 +25,11 @@ const devMode = true
 func ListenAndServe(addr string, api *API) error {
 	log.Println("shiftapi: running in dev mode (shiftapidev build tag)")
 	if specPath := os.Getenv("SHIFTAPI_EXPORT_SPEC"); specPath != "" {
+		if api.docComments {
+			if err := api.applyDocComments(); err != nil {
+				return err
+			}
+		}
 		if err := exportSpec(api, specPath); err != nil {
 			return err
 		}
@@ -3,8 +3,10 @@ package shiftapi
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"reflect"
+	"slices"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3gen"
@@ -19,24 +21,42 @@ import (
 // API automatically serves the OpenAPI spec at GET /openapi.json and
 // interactive documentation at GET /docs.
 type API struct {
-	spec              *openapi3.T
-	asyncSpec         *spec.AsyncAPI
-	specGen           *openapi3gen.Generator
-	mux               *http.ServeMux
-	validate          *validator.Validate
-	maxUploadSize     int64
-	badRequestFn      func(error) any                   // builds the 400 response body from a parse error
-	internalServerFn  func(error) any                   // builds the 500 response body from an unmatched error
-	enumRegistry      map[reflect.Type][]any            // enum values registered via WithEnum
-	globalErrors      []errorEntry                      // error types registered at the API level via WithError
-	middleware        []func(http.Handler) http.Handler // middleware registered at the API level via WithMiddleware
-	staticRespHeaders []staticResponseHeader            // static response headers registered at the API level
+	spec               *openapi3.T
+	asyncSpec          *spec.AsyncAPI
+	specGen            *openapi3gen.Generator
+	mux                *http.ServeMux
+	methods            []string // methods with registered routes, sorted
+	validate           *validator.Validate
+	maxUploadSize      int64
+	badRequestFn       func(error) any                   // builds the 400 response body from a parse error
+	internalServerFn   func(error) any                   // builds the 500 response body from an unmatched error
+	enumRegistry       map[reflect.Type][]any            // enum values registered via WithEnum
+	globalErrors       []errorEntry                      // error types registered at the API level via WithError
+	middleware         []func(http.Handler) http.Handler // middleware registered at the API level via WithMiddleware
+	staticRespHeaders  []staticResponseHeader            // static response headers registered at the API level
+	codecs             []codecEntry                      // body codecs registered via WithCodec; the first is the default
+	scalars            scalarRegistry                    // custom scalar types registered via WithScalarType
+	decodeMode         decodeMode                        // JSON decoding flags registered at the API level
+	maxBodySize        int64                             // request body limit registered at the API level, 0 if none
+	maxDecompressed    int64                             // decompressed body limit; 0 leaves Content-Encoding undecoded
+	contentDecoders    map[string]ContentDecoder         // request Content-Encoding decoders
+	compression        *compressionConfig                // response compression registered at the API level, nil if none
+	contentEncoders    []contentEncoderEntry             // response Content-Encoding encoders, in order of preference
+	security           *securityConfig                   // security requirements registered at the API level, nil if none
+	securitySchemes    map[string]securityScheme         // schemes documented in the spec, by name
+	operationIDs       map[string]string                 // routes by the operation IDs they use in either spec
+	requestTooLargeFn  func(error) any                   // builds the 413 response body when a request body exceeds its limit
+	methodNotAllowedFn func(error) any                   // builds the 405 response body from a *MethodNotAllowedError
+	docComments        bool                              // describe schemas from Go doc comments when exporting the spec
+	docTypes           map[string]reflect.Type           // named struct types behind component schemas, when docComments is set
+	paramDocs          []fieldDoc                        // parameters to describe from field doc comments, when docComments is set
 }
 
 // New creates a new API with the given options. By default the API uses a
 // 32 MB upload limit and the standard [github.com/go-playground/validator/v10]
 // instance. Use [WithInfo], [WithMaxUploadSize], [WithValidator], and
-// [WithExternalDocs] to customize behavior.
+// [WithExternalDocs] to customize behavior. Bodies are JSON unless more codecs
+// are registered with [WithCodec].
 func New(options ...APIOption) *API {
 	api := &API{
 		spec: &openapi3.T{
@@ -49,10 +69,14 @@ func New(options ...APIOption) *API {
 		asyncSpec: &spec.AsyncAPI{
 			DefaultContentType: "application/json",
 		},
-		mux:           http.NewServeMux(),
-		validate:      validator.New(),
-		maxUploadSize: 32 << 20, // 32 MB
-		enumRegistry:  make(map[reflect.Type][]any),
+		mux:             http.NewServeMux(),
+		validate:        validator.New(),
+		maxUploadSize:   32 << 20, // 32 MB
+		enumRegistry:    make(map[reflect.Type][]any),
+		codecs:          slices.Clone(defaultCodecs),
+		scalars:         make(scalarRegistry),
+		contentDecoders: defaultContentDecoders(),
+		contentEncoders: defaultContentEncoders(),
 	}
 	for _, opt := range options {
 		opt.applyToAPI(api)
@@ -63,11 +87,25 @@ func New(options ...APIOption) *API {
 
 	// Set defaults for error response functions if not customized.
 	if api.badRequestFn == nil {
-		api.badRequestFn = func(_ error) any {
+		api.badRequestFn = func(err error) any {
+			if uf, ok := errors.AsType[*UnknownFieldError](err); ok {
+				return &defaultMessage{Message: uf.Error()}
+			}
 			return &defaultMessage{Message: "bad request"}
 		}
 		api.spec.Components.Schemas["BadRequestError"] = messageOnlySchemaRef()
 	}
+	if api.requestTooLargeFn == nil {
+		api.requestTooLargeFn = func(_ error) any {
+			return &defaultMessage{Message: "request entity too large"}
+		}
+	}
+	if api.methodNotAllowedFn == nil {
+		api.methodNotAllowedFn = func(_ error) any {
+			return &defaultMessage{Message: "method not allowed"}
+		}
+		api.spec.Components.Schemas["MethodNotAllowedError"] = messageOnlySchemaRef()
+	}
 	if api.internalServerFn == nil {
 		api.internalServerFn = func(_ error) any {
 			return &defaultMessage{Message: "internal server error"}
@@ -112,11 +150,11 @@ func New(options ...APIOption) *API {
 		api.asyncSpec.Info.Description = api.spec.Info.Description
 	}
 
-	api.mux.HandleFunc("GET /openapi.json", api.serveSpec)
-	api.mux.HandleFunc("GET /asyncapi.json", api.serveAsyncSpec)
-	api.mux.HandleFunc("GET /docs", api.serveDocs)
-	api.mux.HandleFunc("GET /docs/ws", api.serveAsyncDocs)
-	api.mux.HandleFunc("GET /", api.redirectTo("/docs"))
+	api.handle("GET /openapi.json", http.HandlerFunc(api.serveSpec))
+	api.handle("GET /asyncapi.json", http.HandlerFunc(api.serveAsyncSpec))
+	api.handle("GET /docs", http.HandlerFunc(api.serveDocs))
+	api.handle("GET /docs/ws", http.HandlerFunc(api.serveAsyncDocs))
+	api.mux.Handle(docsRedirectPattern, api.redirectTo("/docs"))
 	return api
 }
 
@@ -132,6 +170,26 @@ func (a *API) addStaticResponseHeader(h staticResponseHeader) {
 	a.staticRespHeaders = append(a.staticRespHeaders, h)
 }
 
+func (a *API) addDecodeMode(m decodeMode) {
+	a.decodeMode |= m
+}
+
+func (a *API) setMaxBodySize(n int64) {
+	a.maxBodySize = n
+}
+
+func (a *API) setMaxDecompressedSize(n int64) {
+	a.maxDecompressed = n
+}
+
+func (a *API) setCompression(c *compressionConfig) {
+	a.compression = c
+}
+
+func (a *API) addSecurity(req securityRequirement) {
+	a.security = a.security.with(req)
+}
+
 func (a *API) routerImpl() routerData {
 	return routerData{
 		api:               a,
@@ -139,11 +197,24 @@ func (a *API) routerImpl() routerData {
 		errors:            a.globalErrors,
 		middleware:        a.middleware,
 		staticRespHeaders: a.staticRespHeaders,
+		decodeMode:        a.decodeMode,
+		maxBodySize:       a.maxBodySize,
+		maxDecompressed:   a.maxDecompressed,
+		compression:       a.compression,
+		security:          a.security,
 	}
 }
 
-// ServeHTTP implements http.Handler.
+// ServeHTTP implements http.Handler. Requests for a registered path with an
+// unregistered method are answered by the API: OPTIONS with 204 and other
+// methods with 405, both with an Allow header listing the path's methods.
+// Other unrouted requests get 404, except GET requests, which are redirected
+// to the docs.
 func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := a.mux.Handler(r); pattern == "" {
+		a.serveUnmatchedMethod(w, r, a.allowedMethods(r))
+		return
+	}
 	a.mux.ServeHTTP(w, r)
 }
 
@@ -93,6 +93,43 @@ func WithBadRequestError[T any](fn func(error) T) apiOptionFunc {
 	}
 }
 
+// WithRequestTooLargeError customizes the 413 Request Entity Too Large
+// response returned when a request body exceeds the limit set by
+// [WithMaxBodySize]. The function receives the [*http.MaxBytesError] and
+// returns the value to serialize as the response body. T's type determines the
+// RequestTooLargeError schema in the OpenAPI spec.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithMaxBodySize(1 << 20),
+//	    shiftapi.WithRequestTooLargeError(func(err error) *MyError {
+//	        return &MyError{Code: "TOO_LARGE", Message: err.Error()}
+//	    }),
+//	)
+func WithRequestTooLargeError[T any](fn func(error) T) apiOptionFunc {
+	return func(api *API) {
+		api.requestTooLargeFn = func(err error) any { return fn(err) }
+		registerErrorSchema[T](api, "RequestTooLargeError")
+	}
+}
+
+// WithMethodNotAllowedError customizes the 405 Method Not Allowed response
+// returned when a request's path is routed but its method is not. The
+// function receives a [*MethodNotAllowedError] and returns the value to
+// serialize as the response body. T's type determines the
+// MethodNotAllowedError schema in the OpenAPI spec.
+//
+//	api := shiftapi.New(
+//	    shiftapi.WithMethodNotAllowedError(func(err error) *MyError {
+//	        return &MyError{Code: "METHOD_NOT_ALLOWED", Message: err.Error()}
+//	    }),
+//	)
+func WithMethodNotAllowedError[T any](fn func(error) T) apiOptionFunc {
+	return func(api *API) {
+		api.methodNotAllowedFn = func(err error) any { return fn(err) }
+		registerErrorSchema[T](api, "MethodNotAllowedError")
+	}
+}
+
 // WithInternalServerError customizes the 500 Internal Server Error response
 // returned when a handler returns an error that doesn't match any registered
 // error type. The function receives the unhandled error and returns the value
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"net/url"
 	"slices"
 	"strings"
 	"testing"
@@ -946,6 +947,54 @@ func TestSpecOperationID(t *testing.T) {
 	}
 }
 
+func TestSpecOperationIDOverride(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "GET /users/{id}", func(r *http.Request, in *Empty) (*Empty, error) {
+		return &Empty{}, nil
+	}, shiftapi.WithRouteInfo(shiftapi.RouteInfo{OperationID: "getUser"}))
+	shiftapi.HandleSSE(api, "GET /users/{id}/events", func(r *http.Request, _ struct{}, sse *shiftapi.SSEWriter) error {
+		return nil
+	}, shiftapi.SSESends(
+		shiftapi.SSEEventType[sseMessage]("message"),
+	), shiftapi.WithRouteInfo(shiftapi.RouteInfo{OperationID: "streamUserEvents"}))
+
+	spec := api.Spec()
+	if got := spec.Paths.Find("/users/{id}").Get.OperationID; got != "getUser" {
+		t.Errorf("expected operationId %q, got %q", "getUser", got)
+	}
+	if got := spec.Paths.Find("/users/{id}/events").Get.OperationID; got != "streamUserEvents" {
+		t.Errorf("expected operationId %q, got %q", "streamUserEvents", got)
+	}
+}
+
+func TestSpecOperationIDDuplicatePanics(t *testing.T) {
+	handler := func(r *http.Request, in *Empty) (*Empty, error) { return &Empty{}, nil }
+	tests := map[string]func(api *shiftapi.API){
+		"generated": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /users/{id}", handler)
+			shiftapi.Handle(api, "GET /users/byId", handler)
+		},
+		"override": func(api *shiftapi.API) {
+			shiftapi.Handle(api, "GET /users", handler)
+			shiftapi.Handle(api, "GET /people", handler, shiftapi.WithRouteInfo(shiftapi.RouteInfo{OperationID: "getUsers"}))
+		},
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("expected panic")
+				}
+				if msg := fmt.Sprint(r); !strings.Contains(msg, "duplicate operation ID") {
+					t.Errorf("unexpected panic %q", msg)
+				}
+			}()
+			register(newTestAPI(t))
+		})
+	}
+}
+
 // --- Default error response tests ---
 
 func TestSpecHas422And500ErrorResponses(t *testing.T) {
@@ -2890,6 +2939,93 @@ func TestFormUploadEmptyBody(t *testing.T) {
 	}
 }
 
+// --- URL-encoded form tests ---
+
+func doURLEncodedRequest(t *testing.T, api http.Handler, method, path string, values url.Values) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func TestURLEncodedFormTextFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /form", func(r *http.Request, in FormTextFieldsInput) (*FormTextFieldsResult, error) {
+		return &FormTextFieldsResult{Name: in.Name, Age: in.Age, Score: in.Score, Admin: in.Admin}, nil
+	})
+
+	resp := doURLEncodedRequest(t, api, http.MethodPost, "/form", url.Values{
+		"name":  {"Alice"},
+		"age":   {"30"},
+		"score": {"9.5"},
+		"admin": {"true"},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	result := decodeJSON[FormTextFieldsResult](t, resp)
+	if result.Name != "Alice" || result.Age != 30 || result.Score != 9.5 || !result.Admin {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestURLEncodedFormInvalidValue(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /form", func(r *http.Request, in FormTextFieldsInput) (*FormTextFieldsResult, error) {
+		return &FormTextFieldsResult{}, nil
+	})
+
+	resp := doURLEncodedRequest(t, api, http.MethodPost, "/form", url.Values{"age": {"old"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestURLEncodedFormRejectedWithFileFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /upload", func(r *http.Request, in UploadInput) (*UploadResult, error) {
+		return &UploadResult{}, nil
+	})
+
+	resp := doURLEncodedRequest(t, api, http.MethodPost, "/upload", url.Values{"title": {"x"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestSpecURLEncodedFormContentType(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /form", func(r *http.Request, in FormTextFieldsInput) (*FormTextFieldsResult, error) {
+		return nil, nil
+	})
+
+	content := api.Spec().Paths.Find("/form").Post.RequestBody.Value.Content
+	if content.Get("multipart/form-data") == nil {
+		t.Error("expected multipart/form-data content type")
+	}
+	urlencoded := content.Get("application/x-www-form-urlencoded")
+	if urlencoded == nil {
+		t.Fatal("expected application/x-www-form-urlencoded content type")
+	}
+	if _, ok := urlencoded.Schema.Value.Properties["age"]; !ok {
+		t.Error("expected age property in urlencoded schema")
+	}
+}
+
+func TestSpecURLEncodedOmittedWithFileFields(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /upload", func(r *http.Request, in UploadInput) (*UploadResult, error) {
+		return nil, nil
+	})
+
+	content := api.Spec().Paths.Find("/upload").Post.RequestBody.Value.Content
+	if content.Get("application/x-www-form-urlencoded") != nil {
+		t.Error("form with file fields should not advertise application/x-www-form-urlencoded")
+	}
+}
+
 // --- Accept tag helpers ---
 
 // doMultipartRequestWithContentType sends a multipart request with a file part that has a specific Content-Type.
@@ -2928,7 +3064,7 @@ func TestPostFormAcceptAllowed(t *testing.T) {
 	})
 
 	resp := doMultipartRequestWithContentType(t, api, http.MethodPost, "/upload-image",
-		"image", "photo.png", "image/png", []byte("fake png data"),
+		"image", "photo.png", "image/png", []byte("\x89PNG\r\n\x1a\nfake png data"),
 	)
 	if resp.StatusCode != http.StatusOK {
 		body := readBody(t, resp)
@@ -3010,6 +3146,312 @@ func TestSpecFormNoAcceptNoEncoding(t *testing.T) {
 	}
 }
 
+// --- Upload sniffing and limit tests ---
+
+type LimitedUploadInput struct {
+	Avatar *multipart.FileHeader   `form:"avatar" accept:"image/png" maxsize:"1KB"`
+	Docs   []*multipart.FileHeader `form:"docs" maxsize:"16" maxfiles:"2"`
+}
+
+type JSONUploadInput struct {
+	Config *multipart.FileHeader `form:"config" accept:"application/json" validate:"required"`
+}
+
+func TestPostFormAcceptSniffsContent(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /upload-image", func(r *http.Request, in AcceptUploadInput) (*UploadResult, error) {
+		return &UploadResult{}, nil
+	})
+
+	resp := doMultipartRequestWithContentType(t, api, http.MethodPost, "/upload-image",
+		"image", "totally-a.png", "image/png", []byte("\x7fELF\x02\x01\x01\x00 not an image"),
+	)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for mislabelled upload, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestPostFormAcceptUnsniffableTypeTrustsHeader(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /config", func(r *http.Request, in JSONUploadInput) (*UploadResult, error) {
+		return &UploadResult{Filename: in.Config.Filename}, nil
+	})
+
+	resp := doMultipartRequestWithContentType(t, api, http.MethodPost, "/config",
+		"config", "app.json", "application/json", []byte(`{"debug": true}`),
+	)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doMultipartRequestWithContentType(t, api, http.MethodPost, "/config",
+		"config", "app.json", "application/json", []byte("\x89PNG\r\n\x1a\n"),
+	)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for PNG labelled as JSON, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doMultipartRequestWithContentType(t, api, http.MethodPost, "/config",
+		"config", "app.json", "application/json", []byte("\x00\x01\x02 binary blob"),
+	)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for binary content labelled as JSON, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+type TextUploadInput struct {
+	Notes *multipart.FileHeader `form:"notes" accept:"text/csv,application/xml,application/x-protobuf"`
+}
+
+func TestPostFormAcceptUnsniffableTypeSniffsTextOrBinary(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /notes", func(r *http.Request, in TextUploadInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	tests := []struct {
+		contentType string
+		data        string
+		want        int
+	}{
+		{"text/csv", "a,b\n1,2\n", http.StatusOK},
+		{"text/csv", "\x00\x01\x02\x03", http.StatusBadRequest},
+		{"application/xml", `<?xml version="1.0"?><notes/>`, http.StatusOK},
+		{"application/xml", "\x00\x01\x02\x03", http.StatusBadRequest},
+		{"application/x-protobuf", "\x00\x01\x02\x03", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := doMultipartRequestWithContentType(t, api, http.MethodPost, "/notes",
+			"notes", "notes", tt.contentType, []byte(tt.data),
+		)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %q: expected %d, got %d: %s", tt.contentType, tt.data, tt.want, resp.StatusCode, readBody(t, resp))
+		}
+	}
+}
+
+func TestPostFormMaxSize(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /limited", func(r *http.Request, in LimitedUploadInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 1024)...)
+	resp := doMultipartRequestWithContentType(t, api, http.MethodPost, "/limited",
+		"avatar", "big.png", "image/png", png,
+	)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized file, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doMultipartRequestWithContentType(t, api, http.MethodPost, "/limited",
+		"avatar", "small.png", "image/png", png[:512],
+	)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestPostFormMaxFiles(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /limited", func(r *http.Request, in LimitedUploadInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	resp := doMultipartRequestMultiFiles(t, api, http.MethodPost, "/limited", "docs",
+		[][]byte{[]byte("a"), []byte("b")},
+	)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = doMultipartRequestMultiFiles(t, api, http.MethodPost, "/limited", "docs",
+		[][]byte{[]byte("a"), []byte("b"), []byte("c")},
+	)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for too many files, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestSpecFormFileLimits(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /limited", func(r *http.Request, in LimitedUploadInput) (*Empty, error) {
+		return &Empty{}, nil
+	})
+
+	schema := api.Spec().Paths.Find("/limited").Post.RequestBody.Value.Content.Get("multipart/form-data").Schema.Value
+
+	avatar := schema.Properties["avatar"].Value
+	if avatar.MaxLength == nil || *avatar.MaxLength != 1024 {
+		t.Errorf("expected avatar maxLength 1024, got %v", avatar.MaxLength)
+	}
+	if avatar.Extensions["contentMediaType"] != "image/png" {
+		t.Errorf("expected avatar contentMediaType image/png, got %v", avatar.Extensions["contentMediaType"])
+	}
+
+	docs := schema.Properties["docs"].Value
+	if docs.MaxItems == nil || *docs.MaxItems != 2 {
+		t.Errorf("expected docs maxItems 2, got %v", docs.MaxItems)
+	}
+	if items := docs.Items.Value; items.MaxLength == nil || *items.MaxLength != 16 {
+		t.Errorf("expected docs items maxLength 16, got %v", items.MaxLength)
+	}
+	if api.Spec().Paths.Find("/limited").Post.Responses.Value("413") == nil {
+		t.Error("expected 413 documented for file limits")
+	}
+}
+
+func TestFormFileLimitsInvalidTagPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for maxfiles on a single file field")
+		}
+	}()
+	type In struct {
+		File *multipart.FileHeader `form:"file" maxfiles:"3"`
+	}
+	shiftapi.Handle(newTestAPI(t), "POST /x", func(r *http.Request, in In) (*Empty, error) {
+		return &Empty{}, nil
+	})
+}
+
+// --- JSON form part tests ---
+
+type DocumentMeta struct {
+	Title  string `json:"title" validate:"required"`
+	Public bool   `json:"public" default:"true"`
+}
+
+type CreateDocumentInput struct {
+	Meta   DocumentMeta          `form:"meta"`
+	Labels []string              `form:"labels,json"`
+	File   *multipart.FileHeader `form:"file" validate:"required"`
+}
+
+type CreateDocumentResult struct {
+	Title    string   `json:"title"`
+	Public   bool     `json:"public"`
+	Labels   []string `json:"labels"`
+	Filename string   `json:"filename"`
+}
+
+func createDocument(r *http.Request, in CreateDocumentInput) (*CreateDocumentResult, error) {
+	return &CreateDocumentResult{
+		Title:    in.Meta.Title,
+		Public:   in.Meta.Public,
+		Labels:   in.Labels,
+		Filename: in.File.Filename,
+	}, nil
+}
+
+// doMultipartJSONRequest sends a multipart request with a JSON meta part
+// (optionally as a Blob-style file part) and a file part.
+func doMultipartJSONRequest(t *testing.T, api http.Handler, meta string, metaAsFile bool, fields map[string]string) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	h := make(textproto.MIMEHeader)
+	if metaAsFile {
+		h.Set("Content-Disposition", `form-data; name="meta"; filename="blob"`)
+	} else {
+		h.Set("Content-Disposition", `form-data; name="meta"`)
+	}
+	h.Set("Content-Type", "application/json")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		t.Fatalf("failed to create part: %v", err)
+	}
+	_, _ = part.Write([]byte(meta))
+	for k, v := range fields {
+		_ = w.WriteField(k, v)
+	}
+	fw, err := w.CreateFormFile("file", "doc.txt")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	_, _ = fw.Write([]byte("contents"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/documents", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func TestPostFormJSONPart(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /documents", createDocument)
+
+	for _, asFile := range []bool{false, true} {
+		resp := doMultipartJSONRequest(t, api, `{"title":"Q3 report"}`, asFile, map[string]string{"labels": `["finance","q3"]`})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("asFile=%v: expected 200, got %d: %s", asFile, resp.StatusCode, readBody(t, resp))
+		}
+		got := decodeJSON[CreateDocumentResult](t, resp)
+		if got.Title != "Q3 report" || !got.Public || got.Filename != "doc.txt" {
+			t.Errorf("asFile=%v: unexpected result %+v", asFile, got)
+		}
+		if len(got.Labels) != 2 || got.Labels[1] != "q3" {
+			t.Errorf("asFile=%v: expected labels [finance q3], got %v", asFile, got.Labels)
+		}
+	}
+}
+
+func TestPostFormJSONPartValidated(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /documents", createDocument)
+
+	resp := doMultipartJSONRequest(t, api, `{"public":false}`, false, nil)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+	ve := decodeJSON[shiftapi.ValidationError](t, resp)
+	if len(ve.Errors) != 1 || ve.Errors[0].Field != "Title" {
+		t.Errorf("expected a Title error, got %+v", ve.Errors)
+	}
+}
+
+func TestPostFormJSONPartInvalid(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /documents", createDocument)
+
+	resp := doMultipartJSONRequest(t, api, `{"title":`, false, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+	}
+}
+
+func TestSpecFormJSONPart(t *testing.T) {
+	api := newTestAPI(t)
+	shiftapi.Handle(api, "POST /documents", createDocument)
+
+	spec := api.Spec()
+	content := spec.Paths.Find("/documents").Post.RequestBody.Value.Content.Get("multipart/form-data")
+
+	meta := content.Schema.Value.Properties["meta"]
+	if meta == nil || meta.Ref != "#/components/schemas/DocumentMeta" {
+		t.Fatalf("expected meta to reference DocumentMeta, got %+v", meta)
+	}
+	comp, ok := spec.Components.Schemas["DocumentMeta"]
+	if !ok {
+		t.Fatal("expected DocumentMeta in component schemas")
+	}
+	if !slices.Contains(comp.Value.Required, "title") {
+		t.Errorf("expected title to be required, got %v", comp.Value.Required)
+	}
+	if labels := content.Schema.Value.Properties["labels"]; labels == nil || !labels.Value.Type.Is("array") {
+		t.Errorf("expected labels to be an array schema, got %+v", labels)
+	}
+	for _, name := range []string{"meta", "labels"} {
+		if enc := content.Encoding[name]; enc == nil || enc.ContentType != "application/json" {
+			t.Errorf("expected %s encoding application/json, got %+v", name, enc)
+		}
+	}
+}
+
 // --- Required field inference tests ---
 
 type RequiredInferenceResponse struct {
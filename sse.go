@@ -111,6 +111,11 @@ type sseRouteConfig struct {
 	errors            []errorEntry
 	middleware        []func(http.Handler) http.Handler
 	staticRespHeaders []staticResponseHeader
+	decodeMode        decodeMode
+	maxBodySize       int64
+	maxDecompressed   int64
+	compression       *compressionConfig
+	security          *securityConfig
 	eventVariants     []SSEEventVariant
 }
 
@@ -126,6 +131,26 @@ func (c *sseRouteConfig) addStaticResponseHeader(h staticResponseHeader) {
 	c.staticRespHeaders = append(c.staticRespHeaders, h)
 }
 
+func (c *sseRouteConfig) addDecodeMode(m decodeMode) {
+	c.decodeMode |= m
+}
+
+func (c *sseRouteConfig) setMaxBodySize(n int64) {
+	c.maxBodySize = n
+}
+
+func (c *sseRouteConfig) setMaxDecompressedSize(n int64) {
+	c.maxDecompressed = n
+}
+
+func (c *sseRouteConfig) setCompression(cc *compressionConfig) {
+	c.compression = cc
+}
+
+func (c *sseRouteConfig) addSecurity(req securityRequirement) {
+	c.security = c.security.with(req)
+}
+
 func applySSEOptions(opts []SSEOption) sseRouteConfig {
 	var cfg sseRouteConfig
 	for _, opt := range opts {
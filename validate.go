@@ -109,15 +109,61 @@ func fieldErrorMessage(fe validator.FieldError) string {
 // schemaCustomizer wraps validateSchemaCustomizer and also applies enum
 // values from the API's enum registry when no oneof tag is present.
 func (a *API) schemaCustomizer(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	ft := t
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	// Text scalars (TextUnmarshaler and WithScalarType types) are documented
+	// as strings with their well-known or registered format.
+	if a.scalars.isTextScalar(ft) {
+		if a.scalars.encodesAsString(ft) {
+			*schema = openapi3.Schema{Type: &openapi3.Types{"string"}}
+		}
+		if schema.Type.Is("string") && schema.Format == "" {
+			schema.Format = a.scalars.schemaFormat(ft)
+		}
+	} else if ft.Kind() == reflect.Slice && schema.Items != nil && schema.Items.Ref == "" && schema.Items.Value != nil {
+		// Likewise for the items of a slice of text scalars.
+		et := ft.Elem()
+		for et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		if a.scalars.isTextScalar(et) && a.scalars.encodesAsString(et) {
+			schema.Items.Value = &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: a.scalars.schemaFormat(et)}
+		}
+	}
+	if isDeprecated(tag) {
+		schema.Deprecated = true
+	}
+	if desc := fieldDescription(tag); desc != "" {
+		schema.Description = desc
+	}
+	if a.docComments {
+		et := ft
+		for et.Kind() == reflect.Slice || et.Kind() == reflect.Array || et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		a.recordType(et)
+	}
+	if def, ok := tag.Lookup("default"); ok {
+		v, err := a.scalars.schemaTagValue(t, "default", def)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		schema.Default = v
+	}
+	if ex, ok := tag.Lookup("example"); ok {
+		v, err := a.scalars.schemaTagValue(t, "example", ex)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		schema.Example = v
+	}
 	if err := validateSchemaCustomizer(name, t, tag, schema); err != nil {
 		return err
 	}
 	// If no enum was set by oneof, check the enum registry.
 	if schema.Enum == nil {
-		ft := t
-		for ft.Kind() == reflect.Pointer {
-			ft = ft.Elem()
-		}
 		if vals := a.lookupEnum(ft); vals != nil {
 			schema.Enum = vals
 		}
@@ -287,8 +333,9 @@ func applyRequired(t reflect.Type, schema *openapi3.Schema) {
 		isPointer := field.Type.Kind() == reflect.Pointer
 		hasRequired := hasRule(field.Tag.Get("validate"), "required")
 		omitempty := hasJSONOmitempty(field)
+		_, hasDefault := field.Tag.Lookup("default")
 
-		if hasRequired || (!isPointer && !omitempty) {
+		if hasRequired || (!isPointer && !omitempty && !hasDefault) {
 			schema.Required = append(schema.Required, jsonName)
 		}
 
@@ -311,7 +311,7 @@ func runWSDispatchLoop(r *http.Request, conn *websocket.Conn, ws *WSSender, stat
 			// Handler errors are fatal. If the error matches a registered
 			// type, send it as a structured error frame (distinguishable
 			// from data frames by the "error" field) before closing.
-			status, body := resolveError(hc.internalServerFn, err, hc.errLookup)
+			status, body := hc.resolveError(err)
 			if status != http.StatusInternalServerError {
 				writeWSError(ctx, conn, 4000+status%1000, body)
 			} else {
@@ -338,6 +338,11 @@ type wsRouteConfig struct {
 	errors            []errorEntry
 	middleware        []func(http.Handler) http.Handler
 	staticRespHeaders []staticResponseHeader
+	decodeMode        decodeMode
+	maxBodySize       int64
+	maxDecompressed   int64
+	compression       *compressionConfig
+	security          *securityConfig
 	wsAcceptOptions   *WSAcceptOptions
 }
 
@@ -353,6 +358,26 @@ func (c *wsRouteConfig) addStaticResponseHeader(h staticResponseHeader) {
 	c.staticRespHeaders = append(c.staticRespHeaders, h)
 }
 
+func (c *wsRouteConfig) addDecodeMode(m decodeMode) {
+	c.decodeMode |= m
+}
+
+func (c *wsRouteConfig) setMaxBodySize(n int64) {
+	c.maxBodySize = n
+}
+
+func (c *wsRouteConfig) setMaxDecompressedSize(n int64) {
+	c.maxDecompressed = n
+}
+
+func (c *wsRouteConfig) setCompression(cc *compressionConfig) {
+	c.compression = cc
+}
+
+func (c *wsRouteConfig) addSecurity(req securityRequirement) {
+	c.security = c.security.with(req)
+}
+
 func applyWSOptions(opts []WSOption) wsRouteConfig {
 	var cfg wsRouteConfig
 	for _, opt := range opts {
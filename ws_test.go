@@ -1252,3 +1252,35 @@ func TestWebsocket_NoHandlersPanics(t *testing.T) {
 		),
 	)
 }
+
+func TestHandleWS_OperationIDOverride(t *testing.T) {
+	api := shiftapi.New()
+	shiftapi.HandleWS(api, "GET /ws",
+		shiftapi.Websocket(
+			noSetup,
+			shiftapi.WSSends(shiftapi.WSMessageType[wsServerMsg]("server")),
+			shiftapi.WSOn("echo", func(sender *shiftapi.WSSender, _ struct{}, msg wsClientMsg) error {
+				return sender.Send(wsServerMsg(msg))
+			}),
+		),
+		shiftapi.WithRouteInfo(shiftapi.RouteInfo{OperationID: "echo"}),
+	)
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest("GET", "/asyncapi.json", nil))
+	var spec struct {
+		Channels map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"channels"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	ch := spec.Channels["/ws"]
+	if got := ch["subscribe"].OperationID; got != "subscribeEcho" {
+		t.Errorf("subscribe operationId = %q, want subscribeEcho", got)
+	}
+	if got := ch["publish"].OperationID; got != "publishEcho" {
+		t.Errorf("publish operationId = %q, want publishEcho", got)
+	}
+}